@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Documented error codes; each exits with its number (PC002 -> 2)
+const (
+	PC002 = 2
+	PC003 = 3
+	PC007 = 7
+)
+
+// exitInterrupted is the conventional exit code for a run stopped by SIGINT
+const exitInterrupted = 130
+
+// exitCodeFor maps a result error code such as "PC005" to its exit code
+// Unknown codes map to 2
+func exitCodeFor(code string) int {
+	var n int
+	if _, err := fmt.Sscanf(code, "PC%03d", &n); err != nil || n < 1 || n > 8 {
+		return 2
+	}
+	return n
+}
+
+// logError logs "{timestamp} [{code}] {message}" to stderr
+func logError(code int, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintf(os.Stderr, "%s [PC%03d] %s\n", time.Now().UTC().Format(time.RFC3339), code, msg)
+}
+
+// fatal logs the error and exits with the exit code mapped to the error code
+func fatal(code int, format string, args ...interface{}) {
+	logError(code, format, args...)
+	os.Exit(code)
+}
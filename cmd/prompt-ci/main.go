@@ -1,22 +1,57 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"prompt-ci/internal/provider"
 	"prompt-ci/internal/report"
 	"prompt-ci/internal/runner"
 	"prompt-ci/internal/suite"
+	"prompt-ci/internal/validate"
 )
 
 var (
-	suitePath   string
-	fixturesDir string
-	outDir      string
-	mode        string
-	failFast    bool
+	suitePath     string
+	fixturesDir   string
+	outDir        string
+	mode          string
+	failFast      bool
+	providerName  string
+	modelName     string
+	modelsFile    string
+	mockResponses string
+	baseURL       string
+
+	timeoutMS int
+	retries   int
+	budget    int
+	parallel  int
+	samples   int
+	replay    bool
+	cacheDir  string
+	noCache   bool
+	dryRun    bool
+	shardSpec string
+
+	targetSpecs []string
+
+	caseFilters       []string
+	tagFilters        []string
+	excludeTagFilters []string
+	grepFilter        string
+
+	localHandshake      string
+	localStartupTimeout int
+	localRequestTimeout int
 )
 
 func main() {
@@ -34,29 +69,99 @@ func main() {
 	}
 	validateCmd.Flags().StringVar(&suitePath, "suite", "", "Path to the suite file (required)")
 	validateCmd.Flags().StringVar(&fixturesDir, "fixtures", "./fixtures", "Path to fixtures directory")
+	addFilterFlags(validateCmd)
 	validateCmd.MarkFlagRequired("suite")
 
 	runCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run the eval suite",
-		Long:  "Runs the eval suite against fixtures and produces results.",
+		Long:  "Runs the eval suite against fixtures or a live provider and produces results.",
 		RunE:  runRun,
 	}
 	runCmd.Flags().StringVar(&suitePath, "suite", "", "Path to the suite file (required)")
-	runCmd.Flags().StringVar(&mode, "mode", "fixtures", "Run mode (only 'fixtures' supported in MVP)")
+	runCmd.Flags().StringVar(&mode, "mode", "fixtures", "Run mode ('fixtures' or 'live')")
+	runCmd.Flags().StringVar(&providerName, "provider", "openai", "LLM provider used in live mode: openai, anthropic, local, mock")
+	runCmd.Flags().StringVar(&modelName, "model", "", "Model passed to the provider (default depends on --provider)")
+	runCmd.Flags().StringArrayVar(&targetSpecs, "target", nil, "Run against provider:model (repeatable; replaces --provider/--model and writes comparison.html)")
+	runCmd.Flags().StringVar(&modelsFile, "models-file", "", "YAML file adding or replacing entries in the provider/model compatibility table")
+	runCmd.Flags().StringVar(&baseURL, "base-url", "", "API base URL for HTTP providers (e.g. a local OpenAI-compatible server)")
+	runCmd.Flags().StringVar(&mockResponses, "mock-responses", "", "YAML file of scripted responses for the mock provider")
+	runCmd.Flags().IntVar(&timeoutMS, "timeout", 30000, "Maximum milliseconds per test case (1000-300000)")
+	runCmd.Flags().IntVar(&retries, "retry", 0, "Retry attempts for transient provider errors (0-5)")
+	runCmd.Flags().IntVar(&budget, "budget", 100000, "Maximum cumulative cost in millicents (0 for unlimited)")
+	runCmd.Flags().IntVar(&parallel, "parallel", 1, "Number of cases to run concurrently (1-16)")
+	runCmd.Flags().IntVar(&samples, "samples", 1, "Number of times to run each case; cases can override with samples (1-100)")
+	runCmd.Flags().BoolVar(&replay, "replay", false, "Serve responses from the cache only (implies --mode live; also PROMPT_CI_REPLAY=true)")
+	runCmd.Flags().StringVar(&cacheDir, "cache-dir", provider.DefaultCacheDir, "Directory where live responses are recorded for --replay")
+	runCmd.Flags().BoolVar(&noCache, "no-cache", false, "Do not record live responses in the cache")
+	runCmd.Flags().StringVar(&localHandshake, "local-handshake", provider.LocalDefaultHandshake, "Line the local model prints when ready (empty disables the handshake)")
+	runCmd.Flags().IntVar(&localStartupTimeout, "local-startup-timeout", int(provider.LocalDefaultStartupTimeout.Milliseconds()), "Milliseconds to wait for the local model handshake")
+	runCmd.Flags().IntVar(&localRequestTimeout, "local-request-timeout", int(provider.LocalDefaultRequestTimeout.Milliseconds()), "Milliseconds to wait for each local model response")
 	runCmd.Flags().StringVar(&fixturesDir, "fixtures", "./fixtures", "Path to fixtures directory")
 	runCmd.Flags().StringVar(&outDir, "out", "./out", "Output directory for artifacts")
+	runCmd.Flags().StringVar(&shardSpec, "shard", "", "Only run shard i of n (e.g. 2/4); cases are split by a hash of their ID")
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate the suite and write skipped reports without calling any provider")
 	runCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop on first failure")
+	addFilterFlags(runCmd)
 	runCmd.MarkFlagRequired("suite")
 
+	mergeCmd := &cobra.Command{
+		Use:   "merge [flags] <dir>...",
+		Short: "Merge the output of sharded runs",
+		Long:  "Combines results.json and trace.json from several --shard output directories into one set of artifacts.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runMerge,
+	}
+	mergeCmd.Flags().StringVar(&outDir, "out", "./out", "Output directory for merged artifacts")
+	mergeCmd.Flags().StringVar(&suitePath, "suite", "", "Suite file used to order merged results (default: directory order)")
+
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(mergeCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// addFilterFlags registers the case selection flags shared by run and validate
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&caseFilters, "case", nil, "Only include cases whose ID matches (exact or glob; repeatable)")
+	cmd.Flags().StringSliceVar(&tagFilters, "tag", nil, "Only include cases with any of these tags or categories (repeatable)")
+	cmd.Flags().StringSliceVar(&excludeTagFilters, "exclude-tag", nil, "Skip cases with any of these tags or categories (repeatable)")
+	cmd.Flags().StringVar(&grepFilter, "grep", "", "Only include cases whose ID or prompt matches this regular expression")
+}
+
+// buildFilter creates the case filter from the selection flags and checks
+// that it selects at least one case
+func buildFilter(s *suite.Suite) (*suite.Filter, int) {
+	filter, err := suite.NewFilter(caseFilters, tagFilters, excludeTagFilters, grepFilter)
+	if err != nil {
+		fatal(PC002, "%v", err)
+	}
+	selected := 0
+	for _, c := range s.Cases {
+		if ok, _ := filter.Match(c); ok {
+			selected++
+		}
+	}
+	if selected == 0 && len(s.Cases) > 0 {
+		fatal(PC002, "No cases match the --case/--tag/--exclude-tag/--grep selection")
+	}
+	return filter, selected
+}
+
+// selectedCases returns the cases of s that filter selects
+func selectedCases(s *suite.Suite, filter *suite.Filter) []suite.Case {
+	var cases []suite.Case
+	for _, c := range s.Cases {
+		if ok, _ := filter.Match(c); ok {
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
 func runValidate(cmd *cobra.Command, args []string) error {
 	// Parse suite
 	s, err := suite.ParseFile(suitePath)
@@ -64,24 +169,107 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
+	filter, selected := buildFilter(s)
 
 	// Validate suite
-	if err := suite.ValidateSuite(s, fixturesDir); err != nil {
+	if err := suite.ValidateSuite(s, fixturesDir, filter); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
 
+	if filter != nil {
+		fmt.Printf("Suite '%s' is valid (%d cases, %d selected, %d skipped)\n", s.Name, len(s.Cases), selected, len(s.Cases)-selected)
+		return nil
+	}
 	fmt.Printf("Suite '%s' is valid (%d cases)\n", s.Name, len(s.Cases))
 	return nil
 }
 
 func runRun(cmd *cobra.Command, args []string) error {
+	// Replay mode answers from the cache and never calls a provider
+	if os.Getenv("PROMPT_CI_REPLAY") == "true" {
+		replay = true
+	}
+	if replay {
+		if cmd.Flags().Changed("mode") && mode != "live" {
+			fatal(PC002, "--replay cannot be combined with --mode %s", mode)
+		}
+		mode = "live"
+	}
+
 	// Validate mode
-	if mode != "fixtures" {
-		fmt.Fprintf(os.Stderr, "Error: only 'fixtures' mode is supported in MVP\n")
+	if mode != "fixtures" && mode != "live" {
+		fmt.Fprintf(os.Stderr, "Error: invalid mode '%s' (must be 'fixtures' or 'live')\n", mode)
 		os.Exit(2)
 	}
 
+	// Validate numeric flags
+	if timeoutMS < 1000 || timeoutMS > 300000 {
+		fatal(PC002, "--timeout must be between 1000 and 300000 milliseconds, got %d", timeoutMS)
+	}
+	if retries < 0 || retries > 5 {
+		fatal(PC002, "--retry must be between 0 and 5, got %d", retries)
+	}
+	if parallel < 1 || parallel > 16 {
+		fatal(PC002, "--parallel must be between 1 and 16, got %d", parallel)
+	}
+	if samples < 1 || samples > suite.MaxSamples {
+		fatal(PC002, "--samples must be between 1 and %d, got %d", suite.MaxSamples, samples)
+	}
+	if budget < 0 {
+		fatal(PC002, "--budget must be 0 (unlimited) or positive, got %d", budget)
+	}
+
+	var shard *suite.Shard
+	if shardSpec != "" {
+		sh, err := suite.ParseShard(shardSpec)
+		if err != nil {
+			fatal(PC002, "%v", err)
+		}
+		shard = &sh
+	}
+
+	// --target runs the suite against several provider/model pairs
+	if len(targetSpecs) > 0 {
+		if cmd.Flags().Changed("mode") && mode != "live" {
+			fatal(PC002, "--target cannot be combined with --mode %s", mode)
+		}
+		if cmd.Flags().Changed("provider") || cmd.Flags().Changed("model") {
+			fatal(PC002, "--target cannot be combined with --provider or --model")
+		}
+		mode = "live"
+	}
+	targets, err := parseTargets(targetSpecs)
+	if err != nil {
+		fatal(PC002, "%v", err)
+	}
+
+	// Check credentials before any file I/O; replay and dry runs make no API calls
+	if mode == "live" && !replay && !dryRun {
+		for _, t := range targets {
+			if err := provider.CheckCredentials(t.provider); err != nil {
+				fatal(PC003, "%v", err)
+			}
+		}
+	}
+
+	// Resolve provider and model
+	if mode == "live" {
+		table, err := provider.LoadModelTable(modelsFile)
+		if err != nil {
+			fatal(PC002, "%v", err)
+		}
+		for i := range targets {
+			targets[i].model, err = table.Resolve(targets[i].provider, targets[i].model)
+			if err != nil {
+				fatal(PC002, "%v", err)
+			}
+		}
+		if err := checkDuplicateTargets(targets); err != nil {
+			fatal(PC002, "%v", err)
+		}
+	}
+
 	// Parse suite
 	s, err := suite.ParseFile(suitePath)
 	if err != nil {
@@ -89,14 +277,140 @@ func runRun(cmd *cobra.Command, args []string) error {
 		os.Exit(2)
 	}
 
-	// Validate suite
-	if err := suite.ValidateSuite(s, fixturesDir); err != nil {
+	// Validate suite (fixture files are only required in fixtures mode)
+	requiredFixtures := fixturesDir
+	if mode != "fixtures" {
+		requiredFixtures = ""
+	}
+	filter, _ := buildFilter(s)
+	if err := suite.ValidateSuite(s, requiredFixtures, filter); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(2)
 	}
 
+	// Cases outside the shard belong to another job and are left out entirely
+	if shard != nil {
+		s.Cases = shard.Select(s.Cases)
+	}
+
+	// Tool calls can be issued by real models, which needs GitHub credentials
+	if mode == "live" && !replay && !dryRun && len(s.Tools) > 0 {
+		for _, t := range targets {
+			if t.provider == "mock" {
+				continue
+			}
+			if err := provider.CheckToolCredentials(); err != nil {
+				fatal(PC003, "%v", err)
+			}
+			break
+		}
+	}
+
+	// llm_judge and embedding assertions call out in every mode; replayed
+	// verdicts and embeddings come from the cache
+	if !replay && !dryRun {
+		if err := validate.CheckCredentials(selectedCases(s, filter)); err != nil {
+			fatal(PC003, "%v", err)
+		}
+	}
+
 	// Run suite
-	results, hasError := runner.RunSuite(s, fixturesDir, failFast)
+	info := report.RunInfo{
+		SuiteName: s.Name,
+		Mode:      mode,
+		Replay:    replay,
+		DryRun:    dryRun,
+		StartedAt: time.Now(),
+		MinScore:  s.MinScore,
+	}
+	if shard != nil {
+		info.Shard = shard.String()
+	}
+	if mode == "live" {
+		if len(targets) == 1 {
+			info.Provider = targets[0].provider
+			info.Model = targets[0].model
+		} else {
+			for _, t := range targets {
+				info.Targets = append(info.Targets, t.String())
+			}
+		}
+	}
+	// No costs are incurred in fixtures, replay or dry-run mode, so the budget is ignored
+	if mode == "live" && !replay && !dryRun {
+		info.BudgetMillicents = budget
+	}
+	// Ctrl+C cancels the remaining cases; reports are still written below.
+	// A second Ctrl+C falls back to the default handler and kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// llm_judge verdicts and embeddings are cached next to the responses and
+	// follow --replay
+	judgeOptions := validate.JudgeOptions{Replay: replay}
+	if !noCache {
+		judgeOptions.CacheDir = filepath.Join(cacheDir, "judge")
+	}
+	if modelsFile != "" {
+		table, err := provider.LoadModelTable(modelsFile)
+		if err != nil {
+			fatal(PC002, "%v", err)
+		}
+		judgeOptions.Models = table
+	}
+	validate.SetJudgeOptions(judgeOptions)
+	embeddingOptions := validate.EmbeddingOptions{Replay: replay}
+	if !noCache {
+		embeddingOptions.CacheDir = filepath.Join(cacheDir, "embeddings")
+	}
+	validate.SetEmbeddingOptions(embeddingOptions)
+
+	// Targets run one after another and share the budget
+	var results []suite.Result
+	for _, t := range targets {
+		var outcome runner.Outcome
+		switch {
+		case dryRun:
+			outcome = runner.DryRun(s, filter)
+		case info.BudgetExceeded:
+			outcome = runner.SkipAll(s, filter, runner.BudgetSkipReason)
+			outcome.BudgetExceeded = true
+		default:
+			p := openProvider(t)
+			outcome = runner.RunSuite(ctx, s, runner.Options{
+				Provider:         p,
+				Model:            t.model,
+				FailFast:         failFast,
+				Timeout:          time.Duration(timeoutMS) * time.Millisecond,
+				Retries:          retries,
+				BudgetMillicents: info.BudgetMillicents,
+				SpentMillicents:  info.CostMillicents,
+				Parallel:         parallel,
+				Samples:          samples,
+				Filter:           filter,
+			})
+			// Stop provider processes (e.g. the local model) before exiting
+			if c, ok := p.(io.Closer); ok {
+				c.Close()
+			}
+		}
+
+		if len(targets) > 1 {
+			for i := range outcome.Results {
+				outcome.Results[i].Target = t.String()
+			}
+		}
+		results = append(results, outcome.Results...)
+		info.CostMillicents += outcome.CostMillicents
+		info.BudgetExceeded = info.BudgetExceeded || outcome.BudgetExceeded
+		info.Interrupted = info.Interrupted || outcome.Interrupted
+	}
+	validate.CloseJudges()
+	info.CompletedAt = time.Now()
 
 	// Create output directory
 	if err := os.MkdirAll(outDir, 0755); err != nil {
@@ -115,17 +429,136 @@ func runRun(cmd *cobra.Command, args []string) error {
 		os.Exit(2)
 	}
 
-	if err := report.WriteHTML(outDir, s.Name, results); err != nil {
+	if err := report.WriteHTML(outDir, info, results); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing report.html: %v\n", err)
 		os.Exit(2)
 	}
 
-	if err := report.WriteTrace(outDir, s.Name, results); err != nil {
+	if err := report.WriteTrace(outDir, info, results); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing trace.json: %v\n", err)
 		os.Exit(2)
 	}
 
-	// Calculate summary
+	if len(targets) > 1 {
+		if err := report.WriteComparison(outDir, info, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing comparison.html: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	if exitCode := summarize(results, info); exitCode != 0 {
+		os.Exit(exitCode)
+	}
+	return nil
+}
+
+// target is a provider/model pair that a live run executes against
+type target struct {
+	provider string
+	model    string
+}
+
+// String formats the target as "provider:model"
+func (t target) String() string {
+	return t.provider + ":" + t.model
+}
+
+// parseTargets parses --target values of the form provider[:model]
+// Without --target the run has a single target from --provider and --model
+func parseTargets(specs []string) ([]target, error) {
+	if len(specs) == 0 {
+		return []target{{provider: providerName, model: modelName}}, nil
+	}
+	var targets []target
+	for _, spec := range specs {
+		name, model, _ := strings.Cut(spec, ":")
+		if name == "" {
+			return nil, fmt.Errorf("invalid --target '%s' (expected provider:model)", spec)
+		}
+		targets = append(targets, target{provider: name, model: model})
+	}
+	return targets, nil
+}
+
+// checkDuplicateTargets rejects targets that resolve to the same pair
+func checkDuplicateTargets(targets []target) error {
+	seen := make(map[target]bool)
+	for _, t := range targets {
+		if seen[t] {
+			return fmt.Errorf("--target %s is listed more than once", t)
+		}
+		seen[t] = true
+	}
+	return nil
+}
+
+// openProvider selects the response source for a target: fixtures, the
+// replay cache, or the live provider wrapped by the cache recorder
+func openProvider(t target) provider.Provider {
+	cache := &provider.Cache{Dir: cacheDir}
+	switch {
+	case mode == "fixtures":
+		return provider.NewFixtures(fixturesDir)
+	case replay:
+		return provider.NewReplay(cache, t.provider, t.model)
+	}
+
+	p, err := provider.New(t.provider, provider.Config{
+		Model:               t.model,
+		BaseURL:             baseURL,
+		MockResponses:       mockResponses,
+		LocalHandshake:      localHandshake,
+		LocalStartupTimeout: time.Duration(localStartupTimeout) * time.Millisecond,
+		LocalRequestTimeout: time.Duration(localRequestTimeout) * time.Millisecond,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if noCache {
+		return p
+	}
+	return provider.NewRecorder(p, cache, t.model)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	// The suite only provides the case order
+	var order []string
+	if suitePath != "" {
+		s, err := suite.ParseFile(suitePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		for _, c := range s.Cases {
+			order = append(order, c.ID)
+		}
+	}
+
+	merged, err := report.Merge(args, order)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(2)
+	}
+	if err := merged.Write(outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Error %v\n", err)
+		os.Exit(2)
+	}
+
+	if exitCode := summarize(merged.Results, merged.Info); exitCode != 0 {
+		os.Exit(exitCode)
+	}
+	return nil
+}
+
+// summarize prints the summary line and returns the exit code for a run;
+// the highest code takes precedence
+func summarize(results []suite.Result, info report.RunInfo) int {
 	passed := 0
 	failed := 0
 	errors := 0
@@ -143,12 +576,35 @@ func runRun(cmd *cobra.Command, args []string) error {
 	// Print summary (stable format for piping)
 	fmt.Printf("prompt-ci: %d/%d cases passed\n", passed, len(results))
 
-	// Determine exit code
-	if hasError {
-		os.Exit(2)
+	// A suite min_score replaces individual case failures as the gate
+	exitCode := 0
+	if info.MinScore != nil {
+		if score, ok := suite.SuiteScore(results); ok && score < *info.MinScore {
+			fmt.Fprintf(os.Stderr, "prompt-ci: suite score %.2f is below min_score %.2f\n", score, *info.MinScore)
+			exitCode = 1
+		}
+	} else if failed > 0 {
+		exitCode = 1
 	}
-	if failed > 0 {
-		os.Exit(1)
+	if errors > 0 {
+		exitCode = 2
 	}
-	return nil
+	for _, r := range results {
+		if r.ErrorCode != "" {
+			if code := exitCodeFor(r.ErrorCode); code > exitCode {
+				exitCode = code
+			}
+		}
+	}
+	if info.BudgetExceeded {
+		logError(PC007, "Run terminated: budget limit of %d millicents exceeded at %d millicents", info.BudgetMillicents, info.CostMillicents)
+		if PC007 > exitCode {
+			exitCode = PC007
+		}
+	}
+	if info.Interrupted {
+		fmt.Fprintln(os.Stderr, "prompt-ci: interrupted, partial results written to", outDir)
+		exitCode = exitInterrupted
+	}
+	return exitCode
 }
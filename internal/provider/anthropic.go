@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com/v1"
+	anthropicDefaultModel   = "claude-sonnet-4-20250514"
+	anthropicVersion        = "2023-06-01"
+	anthropicMaxTokens      = 4096
+)
+
+func init() {
+	Register("anthropic", func(cfg Config) (Provider, error) {
+		return NewAnthropic(cfg)
+	})
+}
+
+// Anthropic calls the Anthropic Messages API
+type Anthropic struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewAnthropic creates an Anthropic provider using ANTHROPIC_API_KEY
+// The base URL comes from cfg.BaseURL, then ANTHROPIC_BASE_URL, then the public API
+func NewAnthropic(cfg Config) (*Anthropic, error) {
+	if err := CheckCredentials("anthropic"); err != nil {
+		return nil, err
+	}
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("ANTHROPIC_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = anthropicDefaultModel
+	}
+
+	return &Anthropic{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{},
+	}, nil
+}
+
+// Name returns the provider identifier
+func (a *Anthropic) Name() string {
+	return "anthropic"
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type  string                 `json:"type"`
+	Text  string                 `json:"text,omitempty"`
+	ID    string                 `json:"id,omitempty"`
+	Name  string                 `json:"name,omitempty"`
+	Input map[string]interface{} `json:"input,omitempty"`
+}
+
+type anthropicResponse struct {
+	Content []anthropicContentBlock `json:"content"`
+	Usage   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends the conversation as messages; system messages are joined
+// into the top-level system prompt as the Messages API requires
+func (a *Anthropic) Complete(ctx context.Context, req Request) (*Response, error) {
+	model := req.Model
+	if model == "" {
+		model = a.model
+	}
+
+	body := anthropicRequest{
+		Model:     model,
+		MaxTokens: anthropicMaxTokens,
+	}
+	var system []string
+	for _, m := range req.Conversation() {
+		if m.Role == suite.RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		body.Messages = append(body.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	body.System = strings.Join(system, "\n\n")
+	for _, t := range req.Tools {
+		schema := t.Args
+		if schema == nil {
+			// input_schema is mandatory in the Messages API
+			schema = map[string]interface{}{"type": "object"}
+		}
+		body.Tools = append(body.Tools, anthropicTool{
+			Name:        t.Name,
+			Description: t.Description,
+			InputSchema: schema,
+		})
+	}
+
+	headers := map[string]string{
+		"x-api-key":         a.apiKey,
+		"anthropic-version": anthropicVersion,
+	}
+
+	start := time.Now()
+	var out anthropicResponse
+	if err := postJSON(ctx, a.client, a.baseURL+"/messages", headers, body, &out); err != nil {
+		return nil, err
+	}
+	latency := time.Since(start).Milliseconds()
+
+	resp := &Response{
+		InputTokens:  out.Usage.InputTokens,
+		OutputTokens: out.Usage.OutputTokens,
+		LatencyMS:    latency,
+	}
+
+	// Concatenate text blocks; tool_use blocks become structured tool calls
+	var text []string
+	for _, block := range out.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{Name: block.Name, Args: block.Input})
+		}
+	}
+	resp.Content = strings.Join(text, "\n")
+	resp.CostMillicents = CostMillicents(a.Name(), model, resp.InputTokens, resp.OutputTokens)
+
+	return resp, nil
+}
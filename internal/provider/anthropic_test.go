@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+// newAnthropicServer returns an Anthropic client pointed at a test server
+// that runs handler
+func newAnthropicServer(t *testing.T, handler http.HandlerFunc) *Anthropic {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("ANTHROPIC_API_KEY", "test-key")
+	a, err := NewAnthropic(Config{BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestAnthropicCompleteRequestShape(t *testing.T) {
+	var got anthropicRequest
+	a := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %s, want /v1/messages", r.URL.Path)
+		}
+		if key := r.Header.Get("x-api-key"); key != "test-key" {
+			t.Errorf("x-api-key = %q", key)
+		}
+		if v := r.Header.Get("anthropic-version"); v != anthropicVersion {
+			t.Errorf("anthropic-version = %q, want %q", v, anthropicVersion)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, `{
+			"content": [
+				{"type": "text", "text": "Looking at the diff."},
+				{"type": "tool_use", "id": "tu_1", "name": "open_pr_comment", "input": {"body": "LGTM"}},
+				{"type": "text", "text": "Done."}
+			],
+			"usage": {"input_tokens": 1000, "output_tokens": 100}
+		}`)
+	})
+
+	resp, err := a.Complete(context.Background(), Request{
+		Messages: []suite.Message{
+			{Role: suite.RoleSystem, Content: "Be terse."},
+			{Role: suite.RoleSystem, Content: "Cite sources."},
+			{Role: suite.RoleUser, Content: "Review this PR"},
+			{Role: suite.RoleAssistant, Content: "Which one?"},
+			{Role: suite.RoleUser, Content: "#42"},
+		},
+		Prompt: "#42",
+		Tools: []suite.Tool{
+			{Name: "open_pr_comment", Description: "Comment on a PR", Args: map[string]interface{}{"type": "object", "required": []interface{}{"body"}}},
+			{Name: "no_args"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if got.Model != anthropicDefaultModel || got.MaxTokens != anthropicMaxTokens {
+		t.Errorf("model/max_tokens = %q/%d", got.Model, got.MaxTokens)
+	}
+	// System messages move to the top-level system prompt
+	if got.System != "Be terse.\n\nCite sources." {
+		t.Errorf("system = %q", got.System)
+	}
+	if len(got.Messages) != 3 || got.Messages[0].Role != "user" || got.Messages[1].Role != "assistant" || got.Messages[2].Content != "#42" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if len(got.Tools) != 2 || got.Tools[0].InputSchema["required"] == nil {
+		t.Fatalf("tools = %+v", got.Tools)
+	}
+	// input_schema is mandatory, so a tool without args gets an empty object schema
+	if got.Tools[1].InputSchema["type"] != "object" {
+		t.Errorf("tool without args: input_schema = %v", got.Tools[1].InputSchema)
+	}
+
+	if resp.Content != "Looking at the diff.\nDone." {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "open_pr_comment" || resp.ToolCalls[0].Args["body"] != "LGTM" {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.InputTokens != 1000 || resp.OutputTokens != 100 {
+		t.Errorf("tokens = %d/%d, want 1000/100", resp.InputTokens, resp.OutputTokens)
+	}
+	if want := CostMillicents("anthropic", anthropicDefaultModel, 1000, 100); resp.CostMillicents != want {
+		t.Errorf("cost = %d, want %d", resp.CostMillicents, want)
+	}
+}
+
+func TestAnthropicCompleteErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		transient  bool
+		wantDelay  time.Duration
+	}{
+		{name: "rate limited", status: 429, retryAfter: "3", transient: true, wantDelay: 3 * time.Second},
+		{name: "overloaded", status: 529, transient: true},
+		{name: "invalid request", status: 400},
+		{name: "forbidden", status: 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, `{"type": "error", "error": {"type": "some_error", "message": "nope"}}`)
+			})
+
+			_, err := a.Complete(context.Background(), Request{Prompt: "hello"})
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("err = %v, want *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", httpErr.StatusCode, tt.status)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient = %v, want %v", IsTransient(err), tt.transient)
+			}
+			delay, ok := RetryAfter(err)
+			if ok != (tt.wantDelay > 0) || delay != tt.wantDelay {
+				t.Errorf("RetryAfter = %s, %v; want %s", delay, ok, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestAnthropicCompleteCancelled(t *testing.T) {
+	release := make(chan struct{})
+	a := newAnthropicServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := a.Complete(ctx, Request{Prompt: "hello"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if IsTransient(err) {
+		t.Errorf("a cancelled request must not be retried")
+	}
+}
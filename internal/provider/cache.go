@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+const (
+	// DefaultCacheDir is where live responses are recorded
+	DefaultCacheDir = ".prompt-ci-cache"
+	// CacheTTL is how long a cache entry stays valid
+	CacheTTL = 7 * 24 * time.Hour
+)
+
+// CacheMissError is returned in replay mode when a case has no cache entry
+type CacheMissError struct {
+	CaseID string
+}
+
+func (e *CacheMissError) Error() string {
+	return fmt.Sprintf("Replay mode: cache miss for case '%s'", e.CaseID)
+}
+
+// Cache stores provider responses on disk as {dir}/{key[:2]}/{key}.json
+type Cache struct {
+	Dir string
+}
+
+type cacheEntry struct {
+	CreatedAt    time.Time  `json:"created_at"`
+	Provider     string     `json:"provider"`
+	Model        string     `json:"model"`
+	Content      string     `json:"content"`
+	ToolCalls    []ToolCall `json:"tool_calls,omitempty"`
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+}
+
+// CacheKey computes SHA256(provider 0x00 model 0x00 prompt 0x00 tools_hash)
+// where prompt has trailing whitespace stripped and tools_hash is the SHA-256
+// of the tools serialized as JSON with sorted keys. For multi-turn cases the
+// prompt is the request transcript, so each turn gets its own entry.
+func CacheKey(providerName, model, prompt string, tools []suite.Tool) string {
+	h := sha256.New()
+	h.Write([]byte(providerName))
+	h.Write([]byte{0})
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.TrimRight(prompt, " \t\r\n")))
+	h.Write([]byte{0})
+	h.Write([]byte(toolsHash(tools)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// toolsHash hashes the tools array; no tools hashes the empty string
+func toolsHash(tools []suite.Tool) string {
+	if len(tools) == 0 {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:])
+	}
+
+	// Maps marshal with sorted keys, giving a canonical serialization
+	canonical := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		canonical = append(canonical, map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+			"args":        t.Args,
+			"returns":     t.Returns,
+		})
+	}
+	data, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+// Get returns the cached response for key, treating expired entries as misses
+func (c *Cache) Get(key string) (*Response, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	if time.Since(entry.CreatedAt) > CacheTTL {
+		return nil, false
+	}
+
+	return &Response{
+		Content:      entry.Content,
+		ToolCalls:    entry.ToolCalls,
+		InputTokens:  entry.InputTokens,
+		OutputTokens: entry.OutputTokens,
+		CacheHit:     true,
+	}, true
+}
+
+// Put stores resp under key
+func (c *Cache) Put(key, providerName, model string, resp *Response) error {
+	entry := cacheEntry{
+		CreatedAt:    time.Now().UTC(),
+		Provider:     providerName,
+		Model:        model,
+		Content:      resp.Content,
+		ToolCalls:    resp.ToolCalls,
+		InputTokens:  resp.InputTokens,
+		OutputTokens: resp.OutputTokens,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(c.path(key), data)
+}
+
+// WriteFileAtomic writes data to path through a uniquely named temp file in
+// the same directory and a rename, so readers never see partial entries and
+// concurrent writers of the same path do not clobber each other's temp file
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Cached records live responses into a Cache, or in replay mode serves
+// responses only from the cache without calling the provider
+type Cached struct {
+	inner        Provider
+	cache        *Cache
+	providerName string
+	model        string
+	replay       bool
+}
+
+// NewRecorder wraps inner so every successful response is written to cache
+func NewRecorder(inner Provider, cache *Cache, model string) *Cached {
+	return &Cached{inner: inner, cache: cache, providerName: inner.Name(), model: model}
+}
+
+// NewReplay creates a provider that answers from cache only; no provider
+// is constructed, so no credentials or network access are needed
+func NewReplay(cache *Cache, providerName, model string) *Cached {
+	return &Cached{cache: cache, providerName: providerName, model: model, replay: true}
+}
+
+// Name returns the wrapped provider identifier
+func (c *Cached) Name() string {
+	return c.providerName
+}
+
+// Complete serves from the cache in replay mode, otherwise calls the
+// wrapped provider and records the response
+func (c *Cached) Complete(ctx context.Context, req Request) (*Response, error) {
+	key := CacheKey(c.providerName, c.model, req.Transcript(), req.Tools)
+
+	if c.replay {
+		start := time.Now()
+		resp, ok := c.cache.Get(key)
+		if !ok {
+			return nil, &CacheMissError{CaseID: req.CaseID}
+		}
+		resp.LatencyMS = time.Since(start).Milliseconds()
+		return resp, nil
+	}
+
+	resp, err := c.inner.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.cache.Put(key, c.providerName, c.model, resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write cache entry: %v\n", err)
+	}
+	return resp, nil
+}
+
+// MaxConcurrency forwards the wrapped provider's limit; replay has none
+func (c *Cached) MaxConcurrency() int {
+	if limiter, ok := c.inner.(ConcurrencyLimiter); ok && !c.replay {
+		return limiter.MaxConcurrency()
+	}
+	return 0
+}
+
+// Close closes the wrapped provider if it holds resources
+func (c *Cached) Close() error {
+	if closer, ok := c.inner.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
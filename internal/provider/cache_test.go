@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCachePutConcurrentSameKey(t *testing.T) {
+	cache := &Cache{Dir: t.TempDir()}
+	key := CacheKey("openai", "gpt-4o", "prompt", nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- cache.Put(key, "openai", "gpt-4o", &Response{Content: fmt.Sprintf("reply %d", i)})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	resp, ok := cache.Get(key)
+	if !ok {
+		t.Fatal("Get: entry missing after concurrent Puts")
+	}
+	if !strings.HasPrefix(resp.Content, "reply ") {
+		t.Errorf("Get: content = %q", resp.Content)
+	}
+
+	files, err := os.ReadDir(filepath.Dir(cache.path(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), ".tmp") {
+			t.Errorf("temp file %s left behind", f.Name())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+)
+
+// credentialEnv maps each provider to the environment variable it requires
+var credentialEnv = map[string]string{
+	"openai":    "OPENAI_API_KEY",
+	"anthropic": "ANTHROPIC_API_KEY",
+	"local":     "LOCAL_MODEL_PATH",
+}
+
+// CheckCredentials returns an error if the provider's required environment
+// variable is not set. Providers without credentials (mock) always pass.
+func CheckCredentials(providerName string) error {
+	env, ok := credentialEnv[providerName]
+	if !ok || os.Getenv(env) != "" {
+		return nil
+	}
+	return fmt.Errorf("%s environment variable is required for %s provider", env, providerName)
+}
+
+// CheckToolCredentials returns an error if GITHUB_TOKEN, needed by the
+// open_pr_comment tool, is not set
+func CheckToolCredentials() error {
+	if os.Getenv("GITHUB_TOKEN") == "" {
+		return fmt.Errorf("GITHUB_TOKEN missing or lacks pull_requests:write scope")
+	}
+	return nil
+}
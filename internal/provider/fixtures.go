@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"prompt-ci/internal/suite"
+)
+
+// Fixtures serves saved responses from the fixtures directory
+type Fixtures struct {
+	Dir string
+}
+
+// NewFixtures creates a provider that reads responses from fixturesDir
+func NewFixtures(fixturesDir string) *Fixtures {
+	return &Fixtures{Dir: fixturesDir}
+}
+
+// Name returns the provider identifier
+func (f *Fixtures) Name() string {
+	return "fixtures"
+}
+
+// Complete loads the fixture for the request's case and turn
+func (f *Fixtures) Complete(ctx context.Context, req Request) (*Response, error) {
+	content, err := loadFixtureFile(suite.GetTurnFixturePath(f.Dir, req.CaseID, req.Turn))
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Content: content}, nil
+}
+
+func loadFixtureFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read fixture %s: %w", path, err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,355 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+const (
+	// LocalDefaultHandshake is the line a local model prints once it is ready
+	LocalDefaultHandshake = "READY"
+	// LocalDefaultStartupTimeout bounds how long to wait for the handshake
+	LocalDefaultStartupTimeout = 30 * time.Second
+	// LocalDefaultRequestTimeout bounds how long a single request may take
+	LocalDefaultRequestTimeout = 60 * time.Second
+
+	localDefaultModel = "default"
+	localMaxLineBytes = 16 * 1024 * 1024
+	localStopGrace    = 2 * time.Second
+)
+
+func init() {
+	Register("local", func(cfg Config) (Provider, error) {
+		return NewLocal(cfg)
+	})
+}
+
+// Local runs the executable at LOCAL_MODEL_PATH and exchanges one JSON
+// object per line over its stdin/stdout.
+//
+// Request:  {"id": 1, "case_id": "...", "model": "...", "prompt": "...", "tools": [...],
+//
+//	"messages": [{"role": "user", "content": "..."}]}
+//
+// messages is only sent for multi-turn cases; prompt is always the latest
+// user message.
+// Response: {"id": 1, "content": "...", "tool_calls": [{"name": "...", "args": {...}}],
+//
+//	"input_tokens": 0, "output_tokens": 0, "error": ""}
+//
+// The process is started on first use. When a handshake line is configured,
+// the provider waits for the process to print it before sending requests.
+// Requests are sent one at a time.
+type Local struct {
+	path           string
+	model          string
+	handshake      string
+	startupTimeout time.Duration
+	requestTimeout time.Duration
+
+	// sem holds the single request slot; waiting for it respects the
+	// caller's ctx. The fields below are guarded by holding the slot.
+	sem    chan struct{}
+	proc   *localProcess
+	nextID int
+}
+
+type localProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+}
+
+type localRequest struct {
+	ID     int          `json:"id"`
+	CaseID string       `json:"case_id"`
+	Model  string       `json:"model"`
+	Prompt string       `json:"prompt"`
+	Tools  []suite.Tool `json:"tools,omitempty"`
+
+	Messages []suite.Message `json:"messages,omitempty"`
+}
+
+type localResponse struct {
+	ID           int        `json:"id"`
+	Content      string     `json:"content"`
+	ToolCalls    []ToolCall `json:"tool_calls"`
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+	Error        string     `json:"error"`
+}
+
+// localTimeoutError reports a request that exceeded the request timeout;
+// it is treated as transient by IsTransient
+type localTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *localTimeoutError) Error() string {
+	return fmt.Sprintf("local model request timed out after %s", e.timeout)
+}
+
+// Timeout marks the error as a timeout
+func (e *localTimeoutError) Timeout() bool {
+	return true
+}
+
+// NewLocal creates a local provider for the executable at LOCAL_MODEL_PATH
+func NewLocal(cfg Config) (*Local, error) {
+	if err := CheckCredentials("local"); err != nil {
+		return nil, err
+	}
+	path := os.Getenv("LOCAL_MODEL_PATH")
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("LOCAL_MODEL_PATH: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("LOCAL_MODEL_PATH must point to an executable, got directory %s", path)
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = localDefaultModel
+	}
+	startup := cfg.LocalStartupTimeout
+	if startup <= 0 {
+		startup = LocalDefaultStartupTimeout
+	}
+	request := cfg.LocalRequestTimeout
+	if request <= 0 {
+		request = LocalDefaultRequestTimeout
+	}
+
+	return &Local{
+		path:           path,
+		model:          model,
+		handshake:      cfg.LocalHandshake,
+		startupTimeout: startup,
+		requestTimeout: request,
+		sem:            make(chan struct{}, 1),
+	}, nil
+}
+
+// Name returns the provider identifier
+func (l *Local) Name() string {
+	return "local"
+}
+
+// MaxConcurrency reports that the process handles one request at a time
+func (l *Local) MaxConcurrency() int {
+	return 1
+}
+
+// Complete sends one request line and waits for the matching response line
+func (l *Local) Complete(ctx context.Context, req Request) (*Response, error) {
+	// A caller that gives up while waiting for the slot returns without
+	// touching the process, which may be serving another request
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+
+	if l.proc == nil {
+		proc, err := l.start()
+		if err != nil {
+			return nil, err
+		}
+		l.proc = proc
+	}
+
+	model := req.Model
+	if model == "" {
+		model = l.model
+	}
+	l.nextID++
+	line, err := json.Marshal(localRequest{
+		ID:     l.nextID,
+		CaseID: req.CaseID,
+		Model:  model,
+		Prompt: req.Prompt,
+		Tools:  req.Tools,
+
+		Messages: req.Messages,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	start := time.Now()
+	if _, err := l.proc.stdin.Write(append(line, '\n')); err != nil {
+		l.stop()
+		return nil, fmt.Errorf("failed to write to local model: %w", err)
+	}
+
+	timer := time.NewTimer(l.requestTimeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case text, ok := <-l.proc.lines:
+			if !ok {
+				l.stop()
+				return nil, fmt.Errorf("local model process exited")
+			}
+			var out localResponse
+			if err := json.Unmarshal([]byte(text), &out); err != nil {
+				return nil, fmt.Errorf("local model returned invalid JSON: %w", err)
+			}
+			if out.ID != 0 && out.ID != l.nextID {
+				// Stale reply to a request that already timed out
+				continue
+			}
+			if out.Error != "" {
+				return nil, fmt.Errorf("local model error: %s", out.Error)
+			}
+			return &Response{
+				Content:        out.Content,
+				ToolCalls:      out.ToolCalls,
+				InputTokens:    out.InputTokens,
+				OutputTokens:   out.OutputTokens,
+				LatencyMS:      time.Since(start).Milliseconds(),
+				CostMillicents: CostMillicents(l.Name(), model, out.InputTokens, out.OutputTokens),
+			}, nil
+		case <-timer.C:
+			// The process state is unknown; restart it on the next request
+			l.abort()
+			return nil, &localTimeoutError{timeout: l.requestTimeout}
+		case <-ctx.Done():
+			l.abort()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close stops the local model process if it is running
+func (l *Local) Close() error {
+	l.acquire(context.Background())
+	defer l.release()
+	l.stop()
+	return nil
+}
+
+// acquire takes the request slot, or returns ctx's error if ctx ends first
+func (l *Local) acquire(ctx context.Context) error {
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	// Both may have been ready; a cancelled caller must not send a request
+	if err := ctx.Err(); err != nil {
+		l.release()
+		return err
+	}
+	return nil
+}
+
+func (l *Local) release() {
+	<-l.sem
+}
+
+// start launches the process and waits for the handshake line
+func (l *Local) start() (*localProcess, error) {
+	cmd := exec.Command(l.path)
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start local model %s: %w", l.path, err)
+	}
+
+	proc := &localProcess{cmd: cmd, stdin: stdin, lines: make(chan string)}
+	go func() {
+		defer close(proc.lines)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), localMaxLineBytes)
+		for scanner.Scan() {
+			text := strings.TrimSpace(scanner.Text())
+			if text != "" {
+				proc.lines <- text
+			}
+		}
+	}()
+
+	if l.handshake == "" {
+		return proc, nil
+	}
+
+	timer := time.NewTimer(l.startupTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case text, ok := <-proc.lines:
+			if !ok {
+				proc.kill()
+				return nil, fmt.Errorf("local model exited before handshake %q", l.handshake)
+			}
+			if text == l.handshake {
+				return proc, nil
+			}
+		case <-timer.C:
+			proc.kill()
+			return nil, fmt.Errorf("local model did not print handshake %q within %s", l.handshake, l.startupTimeout)
+		}
+	}
+}
+
+// stop shuts down the current process; the caller must hold the slot
+func (l *Local) stop() {
+	if l.proc == nil {
+		return
+	}
+	l.proc.shutdown()
+	l.proc = nil
+}
+
+// abort kills the current process without waiting for it to finish its
+// in-flight request; the caller must hold the slot
+func (l *Local) abort() {
+	if l.proc == nil {
+		return
+	}
+	l.proc.kill()
+	l.proc = nil
+}
+
+// shutdown closes stdin and waits briefly before killing the process
+func (p *localProcess) shutdown() {
+	p.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		p.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(localStopGrace):
+		p.cmd.Process.Kill()
+		<-done
+	}
+	// Drain any buffered output so the reader goroutine can exit
+	for range p.lines {
+	}
+}
+
+func (p *localProcess) kill() {
+	p.cmd.Process.Kill()
+	p.shutdown()
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+// localStubEnv makes the test binary act as a local model (see TestMain)
+const localStubEnv = "PROMPT_CI_LOCAL_STUB"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(localStubEnv); mode != "" {
+		runLocalStub(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runLocalStub prints the handshake, then answers each request line with
+// the model and prompt it received and the number of messages as input
+// tokens. A prompt of the form "sleep <duration>" delays the reply, and
+// "fail" replies with an error. Mode "exit" quits before the handshake and
+// "silent" never prints it.
+func runLocalStub(mode string) {
+	switch mode {
+	case "exit":
+		return
+	case "silent":
+		time.Sleep(time.Minute)
+		return
+	}
+	fmt.Println("log line before the handshake")
+	fmt.Println("READY")
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req localRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Println(`{"error": "bad request"}`)
+			continue
+		}
+		resp := localResponse{ID: req.ID, Content: req.Model + ": " + req.Prompt, InputTokens: len(req.Messages), OutputTokens: 1}
+		if d, ok := strings.CutPrefix(req.Prompt, "sleep "); ok {
+			delay, _ := time.ParseDuration(d)
+			time.Sleep(delay)
+		}
+		if req.Prompt == "fail" {
+			resp = localResponse{ID: req.ID, Error: "model exploded"}
+		}
+		line, _ := json.Marshal(resp)
+		fmt.Println(string(line))
+	}
+}
+
+// newLocalStub returns a local provider that runs the test binary as the stub
+func newLocalStub(t *testing.T, cfg Config) *Local {
+	t.Helper()
+	return newLocalStubMode(t, cfg, "serve")
+}
+
+func newLocalStubMode(t *testing.T, cfg Config, mode string) *Local {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOCAL_MODEL_PATH", exe)
+	t.Setenv(localStubEnv, mode)
+	if cfg.LocalHandshake == "" {
+		cfg.LocalHandshake = LocalDefaultHandshake
+	}
+	l, err := NewLocal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestLocalComplete(t *testing.T) {
+	l := newLocalStub(t, Config{Model: "tiny"})
+
+	resp, err := l.Complete(context.Background(), Request{CaseID: "c1", Prompt: "hello"})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Content != "tiny: hello" {
+		t.Errorf("content = %q, want %q", resp.Content, "tiny: hello")
+	}
+
+	// The process is reused, and a per-request model overrides the default
+	resp, err = l.Complete(context.Background(), Request{CaseID: "c2", Model: "big", Prompt: "again"})
+	if err != nil {
+		t.Fatalf("second Complete: %v", err)
+	}
+	if resp.Content != "big: again" {
+		t.Errorf("content = %q, want %q", resp.Content, "big: again")
+	}
+}
+
+func TestLocalCompleteSendsMessages(t *testing.T) {
+	l := newLocalStub(t, Config{})
+
+	resp, err := l.Complete(context.Background(), Request{
+		Prompt: "and now?",
+		Messages: []suite.Message{
+			{Role: suite.RoleUser, Content: "hi"},
+			{Role: suite.RoleAssistant, Content: "hello"},
+			{Role: suite.RoleUser, Content: "and now?"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if resp.Content != "default: and now?" || resp.InputTokens != 3 {
+		t.Errorf("response = %q with %d messages, want the latest prompt and 3 messages", resp.Content, resp.InputTokens)
+	}
+}
+
+func TestLocalStartupFailures(t *testing.T) {
+	tests := []struct {
+		mode    string
+		startup time.Duration
+		want    string
+	}{
+		{mode: "exit", startup: 30 * time.Second, want: "exited before handshake"},
+		{mode: "silent", startup: 200 * time.Millisecond, want: "did not print handshake"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			l := newLocalStubMode(t, Config{LocalStartupTimeout: tt.startup}, tt.mode)
+			_, err := l.Complete(context.Background(), Request{Prompt: "hello"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalMissingExecutable(t *testing.T) {
+	t.Setenv("LOCAL_MODEL_PATH", "")
+	if _, err := NewLocal(Config{}); err == nil || !strings.Contains(err.Error(), "LOCAL_MODEL_PATH") {
+		t.Errorf("unset: err = %v", err)
+	}
+	t.Setenv("LOCAL_MODEL_PATH", t.TempDir())
+	if _, err := NewLocal(Config{}); err == nil || !strings.Contains(err.Error(), "directory") {
+		t.Errorf("directory: err = %v", err)
+	}
+}
+
+func TestLocalModelError(t *testing.T) {
+	l := newLocalStub(t, Config{})
+
+	_, err := l.Complete(context.Background(), Request{Prompt: "fail"})
+	if err == nil || !strings.Contains(err.Error(), "model exploded") {
+		t.Fatalf("err = %v, want the model's error", err)
+	}
+}
+
+func TestLocalRequestTimeout(t *testing.T) {
+	l := newLocalStub(t, Config{LocalRequestTimeout: 100 * time.Millisecond})
+
+	_, err := l.Complete(context.Background(), Request{Prompt: "sleep 2s"})
+	var timeout *localTimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("err = %v, want a request timeout", err)
+	}
+	if !IsTransient(err) {
+		t.Errorf("request timeout should be transient")
+	}
+
+	// The stuck process was replaced
+	if _, err := l.Complete(context.Background(), Request{Prompt: "hello"}); err != nil {
+		t.Fatalf("Complete after timeout: %v", err)
+	}
+}
+
+// A caller whose ctx ends while another request is in flight must return
+// without killing the process serving that request
+func TestLocalWaitRespectsContext(t *testing.T) {
+	l := newLocalStub(t, Config{})
+	if _, err := l.Complete(context.Background(), Request{Prompt: "warm up"}); err != nil {
+		t.Fatal(err)
+	}
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := l.Complete(context.Background(), Request{Prompt: "sleep 500ms"})
+		slow <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := l.Complete(ctx, Request{Prompt: "hello"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waiting caller: err = %v, want context.DeadlineExceeded", err)
+	}
+	if waited := time.Since(start); waited > 300*time.Millisecond {
+		t.Errorf("waiting caller returned after %s, want about its own deadline", waited)
+	}
+
+	if err := <-slow; err != nil {
+		t.Fatalf("in-flight request was disturbed: %v", err)
+	}
+}
+
+func TestLocalMaxConcurrency(t *testing.T) {
+	l := newLocalStub(t, Config{})
+	cached := NewRecorder(l, &Cache{Dir: t.TempDir()}, "default")
+	if got := cached.MaxConcurrency(); got != 1 {
+		t.Errorf("recorder MaxConcurrency = %d, want 1", got)
+	}
+	replay := NewReplay(&Cache{Dir: t.TempDir()}, "local", "default")
+	if got := replay.MaxConcurrency(); got != 0 {
+		t.Errorf("replay MaxConcurrency = %d, want 0", got)
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MockResponse is the response returned when no scripted response matches
+const MockResponse = "MOCK_RESPONSE"
+
+// mockTokenCount is the token_count reported for every mock call
+const mockTokenCount = 10
+
+func init() {
+	Register("mock", func(cfg Config) (Provider, error) {
+		return NewMock(cfg.MockResponses)
+	})
+}
+
+// MockScript is the side file format for scripted mock responses
+//
+//	cases:
+//	  grounding_budget_flag: "The default is 100000 [doc:cli#c3]."
+//	prompts:
+//	  - pattern: "(?i)timeout"
+//	    response: "30000 [doc:cli#c2]"
+type MockScript struct {
+	Cases   map[string]string   `yaml:"cases"`
+	Prompts []MockPromptPattern `yaml:"prompts"`
+}
+
+// MockPromptPattern maps a prompt regex to a canned response
+type MockPromptPattern struct {
+	Pattern  string `yaml:"pattern"`
+	Response string `yaml:"response"`
+}
+
+type mockPattern struct {
+	re       *regexp.Regexp
+	response string
+}
+
+// Mock is a deterministic provider for testing suites without credentials
+type Mock struct {
+	cases    map[string]string
+	patterns []mockPattern
+}
+
+// NewMock creates a mock provider, optionally loading scripted responses
+// from scriptPath. An empty path returns MockResponse for every call.
+func NewMock(scriptPath string) (*Mock, error) {
+	m := &Mock{cases: make(map[string]string)}
+	if scriptPath == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock responses file: %w", err)
+	}
+
+	var script MockScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse mock responses file: %w", err)
+	}
+
+	for id, response := range script.Cases {
+		m.cases[id] = response
+	}
+	for i, p := range script.Prompts {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("mock responses prompts[%d]: invalid pattern '%s': %w", i, p.Pattern, err)
+		}
+		m.patterns = append(m.patterns, mockPattern{re: re, response: p.Response})
+	}
+
+	return m, nil
+}
+
+// Name returns the provider identifier
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+// Complete returns the scripted response for the case ID, then the first
+// matching prompt pattern, falling back to MockResponse
+func (m *Mock) Complete(ctx context.Context, req Request) (*Response, error) {
+	return &Response{
+		Content:      m.lookup(req),
+		OutputTokens: mockTokenCount,
+		LatencyMS:    0,
+	}, nil
+}
+
+func (m *Mock) lookup(req Request) string {
+	if req.Turn > 0 {
+		if response, ok := m.cases[fmt.Sprintf("%s.turn%d", req.CaseID, req.Turn)]; ok {
+			return response
+		}
+	}
+	if response, ok := m.cases[req.CaseID]; ok {
+		return response
+	}
+	for _, p := range m.patterns {
+		if p.re.MatchString(req.Prompt) {
+			return p.response
+		}
+	}
+	return MockResponse
+}
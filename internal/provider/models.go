@@ -0,0 +1,98 @@
+package provider
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxModelLength is the longest model identifier accepted
+const MaxModelLength = 64
+
+//go:embed models.yaml
+var builtinModels []byte
+
+// ProviderModels lists the models a provider accepts
+type ProviderModels struct {
+	Default  string   `yaml:"default"`
+	Models   []string `yaml:"models"`
+	Patterns []string `yaml:"patterns"`
+}
+
+// ModelTable maps provider name -> accepted models
+type ModelTable map[string]ProviderModels
+
+// LoadModelTable returns the built-in table, with entries from overridePath
+// (if non-empty) replacing or adding providers
+func LoadModelTable(overridePath string) (ModelTable, error) {
+	table := make(ModelTable)
+	if err := yaml.Unmarshal(builtinModels, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse built-in model table: %w", err)
+	}
+
+	if overridePath == "" {
+		return table, nil
+	}
+
+	data, err := os.ReadFile(overridePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models file: %w", err)
+	}
+	var override ModelTable
+	if err := yaml.Unmarshal(data, &override); err != nil {
+		return nil, fmt.Errorf("failed to parse models file: %w", err)
+	}
+	for name, models := range override {
+		table[name] = models
+	}
+
+	return table, nil
+}
+
+// Providers returns the provider names in the table in sorted order
+func (t ModelTable) Providers() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the model to use for providerName, applying the
+// provider default when model is empty
+func (t ModelTable) Resolve(providerName, model string) (string, error) {
+	entry, ok := t[providerName]
+	if !ok {
+		return "", fmt.Errorf("Unknown provider '%s'; valid providers are: %s", providerName, strings.Join(t.Providers(), ", "))
+	}
+
+	if model == "" {
+		model = entry.Default
+	}
+	if len(model) > MaxModelLength {
+		return "", fmt.Errorf("Model '%s' exceeds %d characters", model, MaxModelLength)
+	}
+
+	for _, m := range entry.Models {
+		if m == model {
+			return model, nil
+		}
+	}
+	for _, p := range entry.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return "", fmt.Errorf("model table: invalid pattern '%s' for provider '%s': %w", p, providerName, err)
+		}
+		if re.MatchString(model) {
+			return model, nil
+		}
+	}
+
+	return "", fmt.Errorf("Model '%s' is not compatible with provider '%s'", model, providerName)
+}
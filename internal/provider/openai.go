@@ -0,0 +1,202 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	openAIDefaultBaseURL = "https://api.openai.com/v1"
+	openAIDefaultModel   = "gpt-4o"
+)
+
+func init() {
+	Register("openai", func(cfg Config) (Provider, error) {
+		return NewOpenAI(cfg)
+	})
+}
+
+// OpenAI calls an OpenAI-compatible chat completions endpoint
+type OpenAI struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAI creates an OpenAI provider using OPENAI_API_KEY
+// The base URL comes from cfg.BaseURL, then OPENAI_BASE_URL, then the public API
+func NewOpenAI(cfg Config) (*OpenAI, error) {
+	if err := CheckCredentials("openai"); err != nil {
+		return nil, err
+	}
+	apiKey := os.Getenv("OPENAI_API_KEY")
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = openAIDefaultBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = openAIDefaultModel
+	}
+
+	return &OpenAI{
+		apiKey:  apiKey,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		model:   model,
+		client:  &http.Client{},
+	}, nil
+}
+
+// Name returns the provider identifier
+func (o *OpenAI) Name() string {
+	return "openai"
+}
+
+type openAIMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Tools    []openAITool    `json:"tools,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// Complete sends the conversation as chat messages
+func (o *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+	model := req.Model
+	if model == "" {
+		model = o.model
+	}
+
+	body := openAIRequest{Model: model}
+	for _, m := range req.Conversation() {
+		body.Messages = append(body.Messages, openAIMessage{Role: m.Role, Content: m.Content})
+	}
+	for _, t := range req.Tools {
+		body.Tools = append(body.Tools, openAITool{
+			Type: "function",
+			Function: openAIFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.Args,
+			},
+		})
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+
+	start := time.Now()
+	var out openAIResponse
+	if err := postJSON(ctx, o.client, o.baseURL+"/chat/completions", headers, body, &out); err != nil {
+		return nil, err
+	}
+	latency := time.Since(start).Milliseconds()
+
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("openai response contained no choices")
+	}
+	msg := out.Choices[0].Message
+
+	resp := &Response{
+		Content:      msg.Content,
+		InputTokens:  out.Usage.PromptTokens,
+		OutputTokens: out.Usage.CompletionTokens,
+		LatencyMS:    latency,
+	}
+	for _, tc := range msg.ToolCalls {
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+			return nil, fmt.Errorf("tool call %s has invalid arguments: %w", tc.Function.Name, err)
+		}
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{Name: tc.Function.Name, Args: args})
+	}
+	resp.CostMillicents = CostMillicents(o.Name(), model, resp.InputTokens, resp.OutputTokens)
+
+	return resp, nil
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+	} `json:"usage"`
+}
+
+// Embed returns one embedding vector per input from the embeddings endpoint,
+// in input order, and the cost of the call in millicents
+func (o *OpenAI) Embed(ctx context.Context, model string, inputs []string) ([][]float64, int, error) {
+	headers := map[string]string{"Authorization": "Bearer " + o.apiKey}
+
+	var out openAIEmbeddingResponse
+	body := openAIEmbeddingRequest{Model: model, Input: inputs}
+	if err := postJSON(ctx, o.client, o.baseURL+"/embeddings", headers, body, &out); err != nil {
+		return nil, 0, err
+	}
+	cost := CostMillicents(o.Name(), model, out.Usage.PromptTokens, 0)
+
+	vectors := make([][]float64, len(inputs))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(inputs) {
+			return nil, cost, fmt.Errorf("embeddings response has out-of-range index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, cost, fmt.Errorf("embeddings response is missing input %d", i)
+		}
+	}
+	return vectors, cost, nil
+}
@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+// newOpenAIServer returns an OpenAI client pointed at a test server that
+// runs handler; the trailing slash of the base URL is trimmed
+func newOpenAIServer(t *testing.T, handler http.HandlerFunc) *OpenAI {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	o, err := NewOpenAI(Config{BaseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestOpenAICompleteRequestShape(t *testing.T) {
+	var got openAIRequest
+	o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, `{
+			"choices": [{"message": {"role": "assistant", "content": "Done.", "tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "open_pr_comment", "arguments": "{\"body\": \"LGTM\"}"}}
+			]}}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 100}
+		}`)
+	})
+
+	resp, err := o.Complete(context.Background(), Request{
+		Model: "gpt-4o-mini",
+		Messages: []suite.Message{
+			{Role: suite.RoleSystem, Content: "Be terse."},
+			{Role: suite.RoleUser, Content: "Review this PR"},
+		},
+		Prompt: "Review this PR",
+		Tools:  []suite.Tool{{Name: "open_pr_comment", Description: "Comment on a PR", Args: map[string]interface{}{"type": "object"}}},
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	if got.Model != "gpt-4o-mini" {
+		t.Errorf("model = %q, want gpt-4o-mini", got.Model)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[1].Content != "Review this PR" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Type != "function" || got.Tools[0].Function.Name != "open_pr_comment" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+
+	if resp.Content != "Done." {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "open_pr_comment" || resp.ToolCalls[0].Args["body"] != "LGTM" {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+	if resp.InputTokens != 1000 || resp.OutputTokens != 100 {
+		t.Errorf("tokens = %d/%d, want 1000/100", resp.InputTokens, resp.OutputTokens)
+	}
+	if want := CostMillicents("openai", "gpt-4o-mini", 1000, 100); resp.CostMillicents != want {
+		t.Errorf("cost = %d, want %d", resp.CostMillicents, want)
+	}
+}
+
+func TestOpenAICompleteDefaultModelAndPrompt(t *testing.T) {
+	var got openAIRequest
+	o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"choices": [{"message": {"role": "assistant", "content": "hi"}}]}`)
+	})
+
+	if _, err := o.Complete(context.Background(), Request{Prompt: "hello"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if got.Model != openAIDefaultModel {
+		t.Errorf("model = %q, want %q", got.Model, openAIDefaultModel)
+	}
+	if len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content != "hello" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if len(got.Tools) != 0 {
+		t.Errorf("tools = %+v, want none", got.Tools)
+	}
+}
+
+func TestOpenAICompleteErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		transient  bool
+		wantDelay  time.Duration
+	}{
+		{name: "rate limited", status: 429, retryAfter: "7", body: `{"error": "slow down"}`, transient: true, wantDelay: 7 * time.Second},
+		{name: "server error", status: 503, body: "upstream unavailable", transient: true},
+		{name: "bad request", status: 400, body: `{"error": "unknown model"}`},
+		{name: "unauthorized", status: 401, body: `{"error": "invalid key"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			_, err := o.Complete(context.Background(), Request{Prompt: "hello"})
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) {
+				t.Fatalf("err = %v, want *HTTPError", err)
+			}
+			if httpErr.StatusCode != tt.status || httpErr.Body != tt.body {
+				t.Errorf("HTTPError = %d %q, want %d %q", httpErr.StatusCode, httpErr.Body, tt.status, tt.body)
+			}
+			if IsTransient(err) != tt.transient {
+				t.Errorf("IsTransient = %v, want %v", IsTransient(err), tt.transient)
+			}
+			delay, ok := RetryAfter(err)
+			if ok != (tt.wantDelay > 0) || delay != tt.wantDelay {
+				t.Errorf("RetryAfter = %s, %v; want %s", delay, ok, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestOpenAICompleteLongErrorBodyIsTruncated(t *testing.T) {
+	o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+		io.WriteString(w, strings.Repeat("x", 2*maxErrorBody))
+	})
+
+	_, err := o.Complete(context.Background(), Request{Prompt: "hello"})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("err = %v, want *HTTPError", err)
+	}
+	if len(httpErr.Body) != maxErrorBody+len("...") {
+		t.Errorf("body length = %d, want %d", len(httpErr.Body), maxErrorBody+len("..."))
+	}
+}
+
+func TestOpenAICompleteMalformedResponses(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "no choices", body: `{"choices": []}`, want: "no choices"},
+		{name: "invalid JSON", body: `not json`, want: "failed to decode response"},
+		{name: "invalid tool arguments", body: `{"choices": [{"message": {"tool_calls": [{"function": {"name": "t", "arguments": "{"}}]}}]}`, want: "invalid arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			})
+			_, err := o.Complete(context.Background(), Request{Prompt: "hello"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenAIEmbed(t *testing.T) {
+	var got openAIEmbeddingRequest
+	o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("path = %s, want /v1/embeddings", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		// Out of order, as the API allows
+		io.WriteString(w, `{
+			"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}],
+			"usage": {"prompt_tokens": 500000}
+		}`)
+	})
+
+	vectors, cost, err := o.Embed(context.Background(), "text-embedding-3-small", []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if got.Model != "text-embedding-3-small" || len(got.Input) != 2 || got.Input[0] != "a" {
+		t.Errorf("request = %+v", got)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("vectors = %v, want them in input order", vectors)
+	}
+	if want := CostMillicents("openai", "text-embedding-3-small", 500000, 0); cost != want || cost == 0 {
+		t.Errorf("cost = %d, want %d", cost, want)
+	}
+}
+
+func TestOpenAIEmbedMissingInput(t *testing.T) {
+	o := newOpenAIServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"data": [{"index": 0, "embedding": [1, 0]}]}`)
+	})
+
+	_, _, err := o.Embed(context.Background(), "text-embedding-3-small", []string{"a", "b"})
+	if err == nil || !strings.Contains(err.Error(), "missing input 1") {
+		t.Errorf("err = %v, want a missing input error", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	future := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		value   string
+		min     time.Duration
+		max     time.Duration
+		comment string
+	}{
+		{value: "", comment: "absent"},
+		{value: "0", comment: "zero seconds"},
+		{value: "-3", comment: "negative"},
+		{value: "soon", comment: "garbage"},
+		{value: "12", min: 12 * time.Second, max: 12 * time.Second, comment: "seconds"},
+		{value: future, min: 25 * time.Second, max: 30 * time.Second, comment: "HTTP date"},
+		{value: "Mon, 01 Jan 2001 00:00:00 GMT", comment: "date in the past"},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value); got < tt.min || got > tt.max {
+			t.Errorf("parseRetryAfter(%q) [%s] = %s, want between %s and %s", tt.value, tt.comment, got, tt.min, tt.max)
+		}
+	}
+}
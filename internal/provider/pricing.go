@@ -0,0 +1,55 @@
+package provider
+
+// Price is the cost of a model in millicents per million tokens
+type Price struct {
+	Input  int
+	Output int
+}
+
+// prices maps provider -> model -> price; the "*" model matches any model
+// not listed. Unlisted API models are charged at the provider's most
+// expensive listed rate so the --budget cap stays conservative.
+// 1 USD = 100000 millicents, so $2.50 per million tokens is 250000
+var prices = map[string]map[string]Price{
+	"openai": {
+		"*":            {Input: 250000, Output: 1000000},
+		"gpt-4o":       {Input: 250000, Output: 1000000},
+		"gpt-4o-mini":  {Input: 15000, Output: 60000},
+		"gpt-4.1":      {Input: 200000, Output: 800000},
+		"gpt-4.1-mini": {Input: 40000, Output: 160000},
+		"gpt-4.1-nano": {Input: 10000, Output: 40000},
+		"o3-mini":      {Input: 110000, Output: 440000},
+		// Embedding models are charged for input only
+		"text-embedding-3-small": {Input: 2000},
+		"text-embedding-3-large": {Input: 13000},
+		"text-embedding-ada-002": {Input: 10000},
+	},
+	"anthropic": {
+		"*":                          {Input: 1500000, Output: 7500000},
+		"claude-sonnet-4-20250514":   {Input: 300000, Output: 1500000},
+		"claude-opus-4-20250514":     {Input: 1500000, Output: 7500000},
+		"claude-3-7-sonnet-20250219": {Input: 300000, Output: 1500000},
+		"claude-3-5-haiku-20241022":  {Input: 80000, Output: 400000},
+	},
+	// Self-hosted and mock models incur no API cost
+	"local": {
+		"*": {Input: 0, Output: 0},
+	},
+	"mock": {
+		"*": {Input: 0, Output: 0},
+	},
+}
+
+// CostMillicents returns the cost of a call in millicents, rounded up
+// Unknown provider/model pairs cost 0
+func CostMillicents(providerName, model string, inputTokens, outputTokens int) int {
+	price, ok := prices[providerName][model]
+	if !ok {
+		price, ok = prices[providerName]["*"]
+	}
+	if !ok {
+		return 0
+	}
+	total := int64(inputTokens)*int64(price.Input) + int64(outputTokens)*int64(price.Output)
+	return int((total + 999999) / 1000000)
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+// Request is the input sent to a provider for a single test case
+type Request struct {
+	CaseID string
+	Model  string
+	// Prompt is the latest user message
+	Prompt string
+	Tools  []suite.Tool
+
+	// Messages is the full conversation for multi-turn cases, ending with
+	// Prompt; nil for single-prompt cases
+	Messages []suite.Message
+	// Turn is the 1-based turn of a multi-turn case (0 otherwise)
+	Turn int
+}
+
+// Conversation returns the messages to send: Messages if set, otherwise
+// Prompt as a single user message
+func (r Request) Conversation() []suite.Message {
+	if len(r.Messages) > 0 {
+		return r.Messages
+	}
+	return []suite.Message{{Role: suite.RoleUser, Content: r.Prompt}}
+}
+
+// Transcript renders the conversation as text, one "role: content" entry
+// per message; a single-prompt request renders as the prompt itself
+func (r Request) Transcript() string {
+	if len(r.Messages) == 0 {
+		return r.Prompt
+	}
+	var b strings.Builder
+	for i, m := range r.Messages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(m.Role)
+		b.WriteString(": ")
+		b.WriteString(m.Content)
+	}
+	return b.String()
+}
+
+// Response is the output returned by a provider
+type Response struct {
+	Content        string
+	ToolCalls      []ToolCall
+	InputTokens    int
+	OutputTokens   int
+	LatencyMS      int64
+	CostMillicents int
+	// CacheHit is set when the response was served from the cache
+	CacheHit bool
+}
+
+// ToolCall is a structured tool invocation returned by the model
+type ToolCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
+}
+
+// Render returns the text that assertions are evaluated against: the
+// response content followed by one name({args}) line per tool call,
+// matching the format of the tool fixtures
+func (r *Response) Render() string {
+	if len(r.ToolCalls) == 0 {
+		return r.Content
+	}
+
+	var b strings.Builder
+	if r.Content != "" {
+		b.WriteString(r.Content)
+		b.WriteString("\n")
+	}
+	for i, tc := range r.ToolCalls {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		args, err := json.Marshal(tc.Args)
+		if err != nil {
+			args = []byte("{}")
+		}
+		fmt.Fprintf(&b, "%s(%s)", tc.Name, args)
+	}
+	return b.String()
+}
+
+// Provider is the interface for all response sources (fixtures or LLM backends)
+type Provider interface {
+	// Name returns the provider identifier, e.g. "fixtures" or "openai"
+	Name() string
+	// Complete returns the response for a single request
+	Complete(ctx context.Context, req Request) (*Response, error)
+}
+
+// ConcurrencyLimiter is implemented by providers that serve a limited number
+// of requests at once, such as a local model behind a single pipe. The
+// runner runs no more cases at a time, so a case does not spend its timeout
+// waiting for the provider. A limit of 0 means unlimited.
+type ConcurrencyLimiter interface {
+	MaxConcurrency() int
+}
+
+// Config holds settings passed to provider constructors
+type Config struct {
+	Model string
+	// BaseURL overrides the API endpoint of HTTP providers
+	BaseURL string
+	// MockResponses is an optional side file of scripted mock responses
+	MockResponses string
+	// LocalHandshake is the ready line the local model prints on startup
+	// (empty disables the handshake)
+	LocalHandshake string
+	// LocalStartupTimeout bounds the wait for the local handshake
+	LocalStartupTimeout time.Duration
+	// LocalRequestTimeout bounds each local model request
+	LocalRequestTimeout time.Duration
+}
+
+// Factory creates a provider from its configuration
+type Factory func(cfg Config) (Provider, error)
+
+var registry = make(map[string]Factory)
+
+// Register makes a provider available to New under the given name
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// New creates the named provider
+func New(name string, cfg Config) (Provider, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider '%s' (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Names returns the registered provider names in sorted order
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
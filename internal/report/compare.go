@@ -0,0 +1,175 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"prompt-ci/internal/suite"
+)
+
+const comparisonTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>prompt-ci Comparison - {{.SuiteName}}</title>
+    <style>
+        * { box-sizing: border-box; margin: 0; padding: 0; }
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; padding: 20px; max-width: 1400px; margin: 0 auto; background: #f5f5f5; }
+        h1 { margin-bottom: 10px; color: #333; }
+        p.nav { margin-bottom: 20px; color: #555; }
+        table { width: 100%; border-collapse: collapse; background: white; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
+        th, td { padding: 12px 15px; text-align: left; border-bottom: 1px solid #eee; vertical-align: top; }
+        th { background: #f8f9fa; font-weight: 600; color: #333; }
+        tfoot td { background: #f8f9fa; font-weight: 600; }
+        .status { padding: 4px 12px; border-radius: 20px; font-size: 0.85em; font-weight: 500; }
+        .status-pass { background: #dcfce7; color: #166534; }
+        .status-fail { background: #fee2e2; color: #991b1b; }
+        .status-error { background: #fef3c7; color: #92400e; }
+        .status-skip { background: #e5e7eb; color: #374151; }
+        .metrics { display: block; margin-top: 4px; color: #6b7280; font-size: 0.85em; }
+    </style>
+</head>
+<body>
+    <h1>prompt-ci Comparison: {{.SuiteName}}</h1>
+    <p class="nav"><a href="report.html">Full report</a></p>
+
+    <table>
+        <thead>
+            <tr>
+                <th>Case ID</th>
+                {{range .Targets}}<th>{{.}}</th>{{end}}
+            </tr>
+        </thead>
+        <tbody>
+            {{range .Rows}}
+            <tr>
+                <td>{{.ID}}</td>
+                {{range .Cells}}
+                <td>
+                    {{if .Status}}
+                    <span class="status status-{{.StatusLower}}">{{.Status}}</span>
+                    <span class="metrics">{{.LatencyMS}}ms &middot; {{.CostMillicents}} millicents</span>
+                    {{else}}
+                    -
+                    {{end}}
+                </td>
+                {{end}}
+            </tr>
+            {{end}}
+        </tbody>
+        <tfoot>
+            <tr>
+                <td>Passed</td>
+                {{range .Totals}}<td>{{.Passed}}/{{.Total}}</td>{{end}}
+            </tr>
+            <tr>
+                <td>Total cost</td>
+                {{range .Totals}}<td>{{.CostMillicents}} millicents</td>{{end}}
+            </tr>
+            <tr>
+                <td>Average latency</td>
+                {{range .Totals}}<td>{{.AvgLatencyMS}}ms</td>{{end}}
+            </tr>
+        </tfoot>
+    </table>
+</body>
+</html>`
+
+type comparisonData struct {
+	SuiteName string
+	Targets   []string
+	Rows      []comparisonRow
+	Totals    []comparisonTotal
+}
+
+type comparisonRow struct {
+	ID    string
+	Cells []comparisonCell
+}
+
+type comparisonCell struct {
+	Status         string
+	StatusLower    string
+	LatencyMS      int
+	CostMillicents int
+}
+
+type comparisonTotal struct {
+	Passed         int
+	Total          int
+	CostMillicents int
+	AvgLatencyMS   int
+}
+
+// WriteComparison writes comparison.html, a case x target grid of status,
+// latency and cost for multi-target runs
+func WriteComparison(outDir string, info RunInfo, results []suite.Result) error {
+	path := filepath.Join(outDir, "comparison.html")
+
+	data := comparisonData{
+		SuiteName: info.SuiteName,
+		Targets:   info.Targets,
+		Totals:    make([]comparisonTotal, len(info.Targets)),
+	}
+	column := make(map[string]int, len(info.Targets))
+	for i, t := range info.Targets {
+		column[t] = i
+	}
+
+	row := make(map[string]int)
+	latencySum := make([]int, len(info.Targets))
+	latencyCount := make([]int, len(info.Targets))
+	for _, r := range results {
+		col, ok := column[r.Target]
+		if !ok {
+			continue
+		}
+		i, ok := row[r.ID]
+		if !ok {
+			i = len(data.Rows)
+			row[r.ID] = i
+			data.Rows = append(data.Rows, comparisonRow{ID: r.ID, Cells: make([]comparisonCell, len(info.Targets))})
+		}
+
+		cell := comparisonCell{
+			Status:      string(r.Status),
+			StatusLower: strings.ToLower(string(r.Status)),
+		}
+		if r.Metrics != nil {
+			cell.LatencyMS = *r.Metrics.Latency
+			cell.CostMillicents = *r.Metrics.Cost
+			latencySum[col] += cell.LatencyMS
+			latencyCount[col]++
+		}
+		data.Rows[i].Cells[col] = cell
+
+		total := &data.Totals[col]
+		total.Total++
+		total.CostMillicents += cell.CostMillicents
+		if r.Status == suite.StatusPass {
+			total.Passed++
+		}
+	}
+	for col := range data.Totals {
+		if latencyCount[col] > 0 {
+			data.Totals[col].AvgLatencyMS = latencySum[col] / latencyCount[col]
+		}
+	}
+
+	tmpl, err := template.New("comparison").Parse(comparisonTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.Execute(f, data)
+}
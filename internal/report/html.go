@@ -26,7 +26,9 @@ const htmlTemplate = `<!DOCTYPE html>
         .summary-card.pass h2 { color: #22c55e; }
         .summary-card.fail h2 { color: #ef4444; }
         .summary-card.error h2 { color: #f59e0b; }
+        .summary-card.skip h2 { color: #6b7280; }
         .summary-card.total h2 { color: #3b82f6; }
+        .summary-card.score h2 { color: #8b5cf6; }
         table { width: 100%; border-collapse: collapse; background: white; border-radius: 8px; overflow: hidden; box-shadow: 0 2px 4px rgba(0,0,0,0.1); }
         th, td { padding: 12px 15px; text-align: left; border-bottom: 1px solid #eee; }
         th { background: #f8f9fa; font-weight: 600; color: #333; }
@@ -40,10 +42,27 @@ const htmlTemplate = `<!DOCTYPE html>
         .details:hover { text-decoration: underline; }
         .failure-reasons { display: none; background: #fef2f2; padding: 15px; margin: 10px 0; border-radius: 4px; font-family: monospace; font-size: 0.9em; white-space: pre-wrap; }
         .failure-reasons.show { display: block; }
+        .banner { background: #fee2e2; color: #991b1b; border: 1px solid #fca5a5; padding: 15px 20px; border-radius: 8px; margin-bottom: 20px; font-weight: 600; }
+        .banner.dry-run { background: #e0e7ff; color: #3730a3; border-color: #a5b4fc; }
+        .targets { margin-bottom: 20px; color: #555; }
+        .skip-reason { color: #6b7280; }
     </style>
 </head>
 <body>
-    <h1>prompt-ci Report: {{.SuiteName}}</h1>
+    <h1>prompt-ci Report: {{.SuiteName}}{{if .DryRun}} (dry run){{end}}</h1>
+
+    {{if .BudgetExceeded}}
+    <div class="banner">Run terminated: budget limit of {{.BudgetMillicents}} millicents exceeded at {{.CostMillicents}} millicents</div>
+    {{end}}
+    {{if .DryRun}}
+    <div class="banner dry-run">Dry run: the suite was validated but no cases were executed</div>
+    {{end}}
+    {{if .ScoreBelowMin}}
+    <div class="banner">Suite score {{printf "%.2f" .SuiteScore}} is below min_score {{printf "%.2f" .MinScore}}</div>
+    {{end}}
+    {{if .Interrupted}}
+    <div class="banner">Run interrupted: results are partial</div>
+    {{end}}
 
     <div class="summary">
         <div class="summary-card pass">
@@ -58,19 +77,36 @@ const htmlTemplate = `<!DOCTYPE html>
             <h2>{{.Errors}}</h2>
             <p>Errors</p>
         </div>
+        <div class="summary-card skip">
+            <h2>{{.Skipped}}</h2>
+            <p>Skipped</p>
+        </div>
+        {{if .HasScore}}
+        <div class="summary-card score">
+            <h2>{{printf "%.2f" .SuiteScore}}</h2>
+            <p>Score{{if .HasMinScore}} (min {{printf "%.2f" .MinScore}}){{end}}</p>
+        </div>
+        {{end}}
         <div class="summary-card total">
             <h2>{{.Total}}</h2>
             <p>Total</p>
         </div>
     </div>
 
+    {{if .Targets}}
+    <p class="targets">Targets: {{range $i, $t := .Targets}}{{if $i}}, {{end}}{{$t}}{{end}} &middot; <a href="comparison.html">Compare targets</a></p>
+    {{end}}
+
     <table>
         <thead>
             <tr>
                 <th>Case ID</th>
+                {{if .Targets}}<th>Target</th>{{end}}
                 <th>Validator</th>
                 <th>Status</th>
                 <th>Duration</th>
+                <th>Score</th>
+                <th>Pass Rate</th>
                 <th>Details</th>
             </tr>
         </thead>
@@ -78,13 +114,18 @@ const htmlTemplate = `<!DOCTYPE html>
             {{range .Results}}
             <tr>
                 <td>{{.ID}}</td>
+                {{if $.Targets}}<td>{{.Target}}</td>{{end}}
                 <td>{{.Validator}}</td>
                 <td><span class="status status-{{.StatusLower}}">{{.Status}}</span></td>
                 <td>{{.DurationMS}}ms</td>
+                <td>{{if .Score}}{{.Score}}{{else}}-{{end}}</td>
+                <td>{{if .PassRate}}{{.PassRate}}{{else}}-{{end}}</td>
                 <td>
                     {{if .FailureReasons}}
                     <span class="details" onclick="this.nextElementSibling.classList.toggle('show')">Show failures</span>
                     <div class="failure-reasons">{{.FailureReasonsText}}</div>
+                    {{else if .SkipReason}}
+                    <span class="skip-reason">{{.SkipReason}}</span>
                     {{else}}
                     -
                     {{end}}
@@ -101,28 +142,58 @@ type htmlData struct {
 	Passed    int
 	Failed    int
 	Errors    int
+	Skipped   int
 	Total     int
 	Results   []htmlResult
+
+	BudgetExceeded   bool
+	BudgetMillicents int
+	CostMillicents   int
+	Interrupted      bool
+	DryRun           bool
+	Targets          []string
+
+	HasScore      bool
+	SuiteScore    float64
+	HasMinScore   bool
+	MinScore      float64
+	ScoreBelowMin bool
 }
 
 type htmlResult struct {
 	ID                 string
+	Target             string
 	Status             string
 	StatusLower        string
 	Validator          string
 	DurationMS         int64
 	FailureReasons     []string
 	FailureReasonsText string
+	SkipReason         string
+	PassRate           string
+	Score              string
 }
 
 // WriteHTML writes the report.html file
-func WriteHTML(outDir, suiteName string, results []suite.Result) error {
+func WriteHTML(outDir string, info RunInfo, results []suite.Result) error {
 	path := filepath.Join(outDir, "report.html")
 
 	data := htmlData{
-		SuiteName: suiteName,
-		Total:     len(results),
+		SuiteName:        info.SuiteName,
+		Total:            len(results),
+		BudgetExceeded:   info.BudgetExceeded,
+		BudgetMillicents: info.BudgetMillicents,
+		CostMillicents:   info.CostMillicents,
+		Interrupted:      info.Interrupted,
+		DryRun:           info.DryRun,
+		Targets:          info.Targets,
+	}
+	data.SuiteScore, data.HasScore = suite.SuiteScore(results)
+	if info.MinScore != nil {
+		data.HasMinScore = true
+		data.MinScore = *info.MinScore
 	}
+	data.ScoreBelowMin = data.HasScore && data.HasMinScore && data.SuiteScore < data.MinScore
 
 	for _, r := range results {
 		switch r.Status {
@@ -132,16 +203,32 @@ func WriteHTML(outDir, suiteName string, results []suite.Result) error {
 			data.Failed++
 		case suite.StatusError:
 			data.Errors++
+		case suite.StatusSkip:
+			data.Skipped++
 		}
 
 		hr := htmlResult{
 			ID:                 r.ID,
+			Target:             r.Target,
 			Status:             string(r.Status),
 			StatusLower:        strings.ToLower(string(r.Status)),
 			Validator:          r.Validator,
 			DurationMS:         r.DurationMS,
 			FailureReasons:     r.FailureReasons,
 			FailureReasonsText: strings.Join(r.FailureReasons, "\n"),
+			SkipReason:         r.SkipReason,
+		}
+		if r.Score != nil {
+			hr.Score = scoreText(*r.Score, r.Assertions)
+		}
+		if r.PassRate != nil {
+			passed := 0
+			for _, sample := range r.Samples {
+				if sample.Status == suite.StatusPass {
+					passed++
+				}
+			}
+			hr.PassRate = fmt.Sprintf("%d/%d (%.0f%%)", passed, len(r.Samples), *r.PassRate*100)
 		}
 		data.Results = append(data.Results, hr)
 	}
@@ -159,3 +246,18 @@ func WriteHTML(outDir, suiteName string, results []suite.Result) error {
 
 	return tmpl.Execute(f, data)
 }
+
+// scoreText formats a case score, adding the weights behind it when the
+// assertions are known, e.g. "0.60 (3/5)". Groups contribute their partial
+// credit, as in suite.Score.
+func scoreText(score float64, assertions []suite.AssertionResult) string {
+	if len(assertions) == 0 {
+		return fmt.Sprintf("%.2f", score)
+	}
+	var passed, total float64
+	for _, a := range assertions {
+		total += a.Weight
+		passed += a.Weight * a.Credit()
+	}
+	return fmt.Sprintf("%.2f (%.3g/%.3g)", score, passed, total)
+}
@@ -0,0 +1,62 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prompt-ci/internal/suite"
+)
+
+func TestWriteHTMLMinScore(t *testing.T) {
+	half, minScore := 0.5, 0.9
+	results := []suite.Result{
+		{ID: "tool_a", Status: suite.StatusPass, Score: &half},
+		{ID: "tool_b", Status: suite.StatusFail, Score: &half, FailureReasons: []string{"[contains] missing"}},
+	}
+
+	tests := []struct {
+		name     string
+		minScore *float64
+		want     []string
+		notWant  []string
+	}{
+		{
+			name:     "below min_score",
+			minScore: &minScore,
+			want:     []string{"Suite score 0.50 is below min_score 0.90", "Score (min 0.90)"},
+		},
+		{
+			name:    "no min_score",
+			notWant: []string{"min_score", "(min "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			info := RunInfo{SuiteName: "s", Mode: "live", MinScore: tt.minScore}
+			if err := WriteHTML(dir, info, results); err != nil {
+				t.Fatalf("WriteHTML: %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, "report.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			html := string(data)
+			if strings.Contains(html, "%!") {
+				t.Errorf("report contains a formatting error: %s", html[strings.Index(html, "%!"):][:60])
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(html, s) {
+					t.Errorf("report does not contain %q", s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(html, s) {
+					t.Errorf("report contains %q", s)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"encoding/xml"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,25 +18,38 @@ type JUnitTestSuites struct {
 
 // JUnitTestSuite represents a test suite
 type JUnitTestSuite struct {
-	XMLName   xml.Name        `xml:"testsuite"`
-	Name      string          `xml:"name,attr"`
-	Tests     int             `xml:"tests,attr"`
-	Failures  int             `xml:"failures,attr"`
-	Errors    int             `xml:"errors,attr"`
-	Skipped   int             `xml:"skipped,attr"`
-	Time      float64         `xml:"time,attr"`
-	TestCases []JUnitTestCase `xml:"testcase"`
+	XMLName    xml.Name         `xml:"testsuite"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       float64          `xml:"time,attr"`
+	Properties *JUnitProperties `xml:"properties,omitempty"`
+	TestCases  []JUnitTestCase  `xml:"testcase"`
 }
 
 // JUnitTestCase represents a test case
 type JUnitTestCase struct {
-	XMLName   xml.Name      `xml:"testcase"`
-	Name      string        `xml:"name,attr"`
-	ClassName string        `xml:"classname,attr"`
-	Time      float64       `xml:"time,attr"`
-	Failure   *JUnitFailure `xml:"failure,omitempty"`
-	Error     *JUnitError   `xml:"error,omitempty"`
-	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+	XMLName    xml.Name         `xml:"testcase"`
+	Name       string           `xml:"name,attr"`
+	ClassName  string           `xml:"classname,attr"`
+	Time       float64          `xml:"time,attr"`
+	Properties *JUnitProperties `xml:"properties,omitempty"`
+	Failure    *JUnitFailure    `xml:"failure,omitempty"`
+	Error      *JUnitError      `xml:"error,omitempty"`
+	Skipped    *JUnitSkipped    `xml:"skipped,omitempty"`
+}
+
+// JUnitProperties holds name/value properties of a suite or test case
+type JUnitProperties struct {
+	Properties []JUnitProperty `xml:"property"`
+}
+
+// JUnitProperty is a single name/value property
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
 }
 
 // JUnitFailure represents a test failure
@@ -61,6 +75,31 @@ type JUnitSkipped struct {
 func WriteJUnit(outDir, suiteName string, results []suite.Result) error {
 	path := filepath.Join(outDir, "junit.xml")
 
+	// Multi-target runs get one test suite per target, in run order
+	var testSuites JUnitTestSuites
+	for _, group := range groupByTarget(results) {
+		name := suiteName
+		if group[0].Target != "" {
+			name = fmt.Sprintf("%s [%s]", suiteName, group[0].Target)
+		}
+		testSuites.TestSuites = append(testSuites.TestSuites, junitSuite(name, group))
+	}
+	if len(testSuites.TestSuites) == 0 {
+		testSuites.TestSuites = append(testSuites.TestSuites, junitSuite(suiteName, nil))
+	}
+
+	data, err := xml.MarshalIndent(testSuites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Add XML header
+	output := []byte(xml.Header + string(data))
+	return os.WriteFile(path, output, 0644)
+}
+
+// junitSuite builds one test suite element from results
+func junitSuite(name string, results []suite.Result) JUnitTestSuite {
 	var testCases []JUnitTestCase
 	failures := 0
 	errors := 0
@@ -74,6 +113,9 @@ func WriteJUnit(outDir, suiteName string, results []suite.Result) error {
 			Time:      float64(r.DurationMS) / 1000.0,
 		}
 		totalTime += tc.Time
+		if r.Score != nil {
+			tc.Properties = scoreProperties(*r.Score)
+		}
 
 		switch r.Status {
 		case suite.StatusFail:
@@ -92,34 +134,53 @@ func WriteJUnit(outDir, suiteName string, results []suite.Result) error {
 			}
 		case suite.StatusSkip:
 			skipped++
+			message := r.SkipReason
+			if message == "" {
+				message = "Test case skipped"
+			}
 			tc.Skipped = &JUnitSkipped{
-				Message: "Test case skipped",
+				Message: message,
 			}
 		}
 
 		testCases = append(testCases, tc)
 	}
 
-	testSuites := JUnitTestSuites{
-		TestSuites: []JUnitTestSuite{
-			{
-				Name:      suiteName,
-				Tests:     len(results),
-				Failures:  failures,
-				Errors:    errors,
-				Skipped:   skipped,
-				Time:      totalTime,
-				TestCases: testCases,
-			},
-		},
+	ts := JUnitTestSuite{
+		Name:      name,
+		Tests:     len(results),
+		Failures:  failures,
+		Errors:    errors,
+		Skipped:   skipped,
+		Time:      totalTime,
+		TestCases: testCases,
 	}
-
-	data, err := xml.MarshalIndent(testSuites, "", "  ")
-	if err != nil {
-		return err
+	if score, ok := suite.SuiteScore(results); ok {
+		ts.Properties = scoreProperties(score)
 	}
+	return ts
+}
 
-	// Add XML header
-	output := []byte(xml.Header + string(data))
-	return os.WriteFile(path, output, 0644)
+// scoreProperties formats a weighted score as a "score" property
+func scoreProperties(score float64) *JUnitProperties {
+	return &JUnitProperties{Properties: []JUnitProperty{
+		{Name: "score", Value: fmt.Sprintf("%.3f", score)},
+	}}
+}
+
+// groupByTarget splits results into runs of the same target, keeping the
+// order in which targets first appear
+func groupByTarget(results []suite.Result) [][]suite.Result {
+	var groups [][]suite.Result
+	index := make(map[string]int)
+	for _, r := range results {
+		i, ok := index[r.Target]
+		if !ok {
+			i = len(groups)
+			index[r.Target] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], r)
+	}
+	return groups
 }
@@ -0,0 +1,213 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"prompt-ci/internal/suite"
+)
+
+// Merged holds the combined artifacts of several shard output directories
+type Merged struct {
+	Info    RunInfo
+	Results []suite.Result
+	Trace   Trace
+}
+
+// Merge reads results.json and trace.json from each directory and combines
+// them. The directories must be exactly the shards 1..n of one run (an
+// unsharded run counts as 1/1). Results follow order (usually the suite's
+// case IDs) when given; cases not in order, or all cases when order is
+// empty, follow directory order.
+func Merge(dirs []string, order []string) (*Merged, error) {
+	m := &Merged{}
+	seen := make(map[string]string)
+	entries := make(map[string]TraceEntry)
+	shardDirs := make(map[int]string)
+	shardCount := 0
+
+	for i, dir := range dirs {
+		var results []suite.Result
+		if err := readJSON(filepath.Join(dir, "results.json"), &results); err != nil {
+			return nil, err
+		}
+		var trace Trace
+		if err := readJSON(filepath.Join(dir, "trace.json"), &trace); err != nil {
+			return nil, err
+		}
+
+		sh := suite.Shard{Index: 1, Count: 1}
+		if trace.Shard != "" {
+			var err error
+			if sh, err = suite.ParseShard(trace.Shard); err != nil {
+				return nil, fmt.Errorf("%s: trace.json: %v", dir, err)
+			}
+		}
+		if i == 0 {
+			shardCount = sh.Count
+		} else if sh.Count != shardCount {
+			return nil, fmt.Errorf("%s: shard %s does not match %d shards from %s", dir, sh, shardCount, dirs[0])
+		}
+		if other, ok := shardDirs[sh.Index]; ok {
+			return nil, fmt.Errorf("shard %s appears in both %s and %s", sh, other, dir)
+		}
+		shardDirs[sh.Index] = dir
+
+		if i == 0 {
+			m.Trace = trace
+			m.Trace.Shard = ""
+			m.Trace.FinalCostMillicents = 0
+			m.Trace.CaseCount = 0
+			m.Trace.Entries = nil
+		} else if trace.SuiteName != m.Trace.SuiteName {
+			return nil, fmt.Errorf("%s: suite '%s' does not match suite '%s' from %s", dir, trace.SuiteName, m.Trace.SuiteName, dirs[0])
+		}
+		if trace.StartedAt < m.Trace.StartedAt {
+			m.Trace.StartedAt = trace.StartedAt
+		}
+		if trace.CompletedAt > m.Trace.CompletedAt {
+			m.Trace.CompletedAt = trace.CompletedAt
+		}
+		m.Trace.BudgetExceeded = m.Trace.BudgetExceeded || trace.BudgetExceeded
+		m.Trace.Interrupted = m.Trace.Interrupted || trace.Interrupted
+		m.Trace.FinalCostMillicents += trace.FinalCostMillicents
+		m.Trace.CaseCount += trace.CaseCount
+
+		for _, t := range trace.Targets {
+			if !containsTarget(m.Trace.Targets, t) {
+				m.Trace.Targets = append(m.Trace.Targets, t)
+			}
+		}
+
+		for _, r := range results {
+			key := resultKey(r.Target, r.ID)
+			if other, ok := seen[key]; ok {
+				return nil, fmt.Errorf("case '%s' appears in both %s and %s", r.ID, other, dir)
+			}
+			seen[key] = dir
+			m.Results = append(m.Results, r)
+		}
+		for _, e := range trace.Entries {
+			entries[resultKey(e.Target, e.CaseID)] = e
+		}
+	}
+
+	var missing []string
+	for index := 1; index <= shardCount; index++ {
+		if _, ok := shardDirs[index]; !ok {
+			missing = append(missing, suite.Shard{Index: index, Count: shardCount}.String())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing shard %s", strings.Join(missing, ", "))
+	}
+
+	if len(order) > 0 {
+		m.Results = sortByOrder(m.Results, order)
+	}
+	m.Trace.SuiteScore = nil
+	if score, ok := suite.SuiteScore(m.Results); ok && !m.Trace.DryRun {
+		m.Trace.SuiteScore = &score
+	}
+	for _, r := range m.Results {
+		if e, ok := entries[resultKey(r.Target, r.ID)]; ok {
+			m.Trace.Entries = append(m.Trace.Entries, e)
+		}
+	}
+
+	started, _ := time.Parse(time.RFC3339, m.Trace.StartedAt)
+	completed, _ := time.Parse(time.RFC3339, m.Trace.CompletedAt)
+	m.Info = RunInfo{
+		SuiteName:        m.Trace.SuiteName,
+		Mode:             m.Trace.Mode,
+		Provider:         m.Trace.Provider,
+		Model:            m.Trace.Model,
+		Replay:           m.Trace.ReplayMode,
+		StartedAt:        started,
+		CompletedAt:      completed,
+		DryRun:           m.Trace.DryRun,
+		BudgetMillicents: m.Trace.BudgetMillicents,
+		CostMillicents:   m.Trace.FinalCostMillicents,
+		BudgetExceeded:   m.Trace.BudgetExceeded,
+		Interrupted:      m.Trace.Interrupted,
+		Targets:          m.Trace.Targets,
+		MinScore:         m.Trace.MinScore,
+	}
+	return m, nil
+}
+
+// Write writes the merged results.json, junit.xml, report.html and trace.json
+func (m *Merged) Write(outDir string) error {
+	if err := WriteResults(outDir, m.Results); err != nil {
+		return fmt.Errorf("writing results.json: %w", err)
+	}
+	if err := WriteJUnit(outDir, m.Info.SuiteName, m.Results); err != nil {
+		return fmt.Errorf("writing junit.xml: %w", err)
+	}
+	if err := WriteHTML(outDir, m.Info, m.Results); err != nil {
+		return fmt.Errorf("writing report.html: %w", err)
+	}
+	if err := writeTrace(filepath.Join(outDir, "trace.json"), m.Trace); err != nil {
+		return fmt.Errorf("writing trace.json: %w", err)
+	}
+	if len(m.Info.Targets) > 0 {
+		if err := WriteComparison(outDir, m.Info, m.Results); err != nil {
+			return fmt.Errorf("writing comparison.html: %w", err)
+		}
+	}
+	return nil
+}
+
+// sortByOrder orders results by target (in order of first appearance) and
+// then by position in order; unknown IDs keep their relative order after
+// the known ones of the same target
+func sortByOrder(results []suite.Result, order []string) []suite.Result {
+	var sorted []suite.Result
+	for _, group := range groupByTarget(results) {
+		byID := make(map[string]suite.Result, len(group))
+		for _, r := range group {
+			byID[r.ID] = r
+		}
+		for _, id := range order {
+			if r, ok := byID[id]; ok {
+				sorted = append(sorted, r)
+				delete(byID, id)
+			}
+		}
+		for _, r := range group {
+			if _, ok := byID[r.ID]; ok {
+				sorted = append(sorted, r)
+			}
+		}
+	}
+	return sorted
+}
+
+// resultKey identifies a result across shards of a multi-target run
+func resultKey(target, caseID string) string {
+	return target + "\x00" + caseID
+}
+
+func containsTarget(targets []string, t string) bool {
+	for _, existing := range targets {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
+
+func readJSON(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prompt-ci/internal/suite"
+)
+
+// writeShard writes the results.json and trace.json of one shard run with
+// one passing case per ID and returns its directory
+func writeShard(t *testing.T, suiteName, shard string, ids ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	var results []suite.Result
+	for _, id := range ids {
+		results = append(results, suite.Result{ID: id, Status: suite.StatusPass})
+	}
+	trace := Trace{
+		SuiteName:           suiteName,
+		Mode:                "live",
+		Shard:               shard,
+		StartedAt:           "2026-01-01T00:00:00Z",
+		CompletedAt:         "2026-01-01T00:01:00Z",
+		FinalCostMillicents: 10 * len(ids),
+		CaseCount:           len(ids),
+	}
+	for name, v := range map[string]interface{}{"results.json": results, "trace.json": trace} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMerge(t *testing.T) {
+	shard1 := writeShard(t, "s", "1/2", "b", "d")
+	shard2 := writeShard(t, "s", "2/2", "a", "c")
+
+	m, err := Merge([]string{shard2, shard1}, []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	var ids []string
+	for _, r := range m.Results {
+		ids = append(ids, r.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a,b,c,d" {
+		t.Errorf("results = %s, want a,b,c,d", got)
+	}
+	if m.Trace.Shard != "" || m.Trace.CaseCount != 4 || m.Info.CostMillicents != 40 {
+		t.Errorf("trace shard %q, %d cases, cost %d; want no shard, 4 cases, cost 40", m.Trace.Shard, m.Trace.CaseCount, m.Info.CostMillicents)
+	}
+}
+
+func TestMergeRejectsMismatchedShards(t *testing.T) {
+	tests := []struct {
+		name string
+		dirs func() []string
+		want string
+	}{
+		{
+			name: "duplicate shard",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "1/2", "a"), writeShard(t, "s", "1/2", "b"), writeShard(t, "s", "2/2", "c")}
+			},
+			want: "shard 1/2 appears in both",
+		},
+		{
+			name: "missing shard",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "1/3", "a"), writeShard(t, "s", "3/3", "c")}
+			},
+			want: "missing shard 2/3",
+		},
+		{
+			name: "different shard counts",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "1/2", "a"), writeShard(t, "s", "2/3", "b")}
+			},
+			want: "does not match 2 shards",
+		},
+		{
+			name: "unsharded run merged twice",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "", "a"), writeShard(t, "s", "", "b")}
+			},
+			want: "shard 1/1 appears in both",
+		},
+		{
+			name: "different run",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "1/2", "a"), writeShard(t, "other", "2/2", "b")}
+			},
+			want: "suite 'other' does not match suite 's'",
+		},
+		{
+			name: "case in two shards",
+			dirs: func() []string {
+				return []string{writeShard(t, "s", "1/2", "a"), writeShard(t, "s", "2/2", "a")}
+			},
+			want: "case 'a' appears in both",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Merge(tt.dirs(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -9,48 +9,137 @@ import (
 	"prompt-ci/internal/suite"
 )
 
+// RunInfo carries run-level metadata shared by the report writers
+type RunInfo struct {
+	SuiteName   string
+	Mode        string
+	Provider    string
+	Model       string
+	Replay      bool
+	StartedAt   time.Time
+	CompletedAt time.Time
+
+	// BudgetMillicents is the --budget limit (0 means unlimited)
+	BudgetMillicents int
+	// CostMillicents is the cumulative cost of the run
+	CostMillicents int
+	// BudgetExceeded is true if the run was terminated by the budget
+	BudgetExceeded bool
+	// Interrupted is true if the run was stopped by SIGINT
+	Interrupted bool
+	// DryRun is true if the suite was only validated, not executed
+	DryRun bool
+	// Shard is the "i/n" slice of the suite this run covered, if sharded
+	Shard string
+	// Targets lists the "provider:model" pairs of a multi-target run
+	Targets []string
+	// MinScore is the suite's min_score gate, if any
+	MinScore *float64
+}
+
 // TraceEntry represents a trace entry for a test case
 type TraceEntry struct {
-	CaseID        string   `json:"case_id"`
-	Mode          string   `json:"mode"`
-	FixturePath   string   `json:"fixture_path,omitempty"`
+	CaseID         string   `json:"case_id"`
+	Target         string   `json:"target,omitempty"`
+	Mode           string   `json:"mode"`
+	FixturePath    string   `json:"fixture_path,omitempty"`
 	CitationsFound []string `json:"citations_found,omitempty"`
-	StartedAt     string   `json:"started_at"`
-	CompletedAt   string   `json:"completed_at"`
+	Prompt         string   `json:"prompt,omitempty"`
+	Response       string   `json:"response,omitempty"`
+	LatencyMS      int64    `json:"latency_ms"`
+	InputTokens    int      `json:"input_tokens"`
+	OutputTokens   int      `json:"output_tokens"`
+	CostMillicents int      `json:"cost_millicents"`
+	CacheHit       bool     `json:"cache_hit"`
+	RetryCount     int      `json:"retry_count"`
+	StartedAt      string   `json:"started_at"`
+	CompletedAt    string   `json:"completed_at"`
 }
 
 // Trace represents the trace file structure
 type Trace struct {
-	SuiteName string       `json:"suite_name"`
-	Mode      string       `json:"mode"`
-	StartedAt string       `json:"started_at"`
-	CompletedAt string     `json:"completed_at"`
-	Entries   []TraceEntry `json:"entries"`
+	SuiteName   string   `json:"suite_name"`
+	Mode        string   `json:"mode"`
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Targets     []string `json:"targets,omitempty"`
+	ReplayMode  bool     `json:"replay_mode,omitempty"`
+	Shard       string   `json:"shard,omitempty"`
+	StartedAt   string   `json:"started_at"`
+	CompletedAt string   `json:"completed_at"`
+
+	BudgetMillicents    int  `json:"budget_millicents,omitempty"`
+	BudgetExceeded      bool `json:"budget_exceeded"`
+	FinalCostMillicents int  `json:"final_cost_millicents"`
+	Interrupted         bool `json:"interrupted,omitempty"`
+	DryRun              bool `json:"dry_run,omitempty"`
+	CaseCount           int  `json:"case_count"`
+
+	SuiteScore *float64 `json:"suite_score,omitempty"`
+	MinScore   *float64 `json:"min_score,omitempty"`
+
+	// Entries is omitted in a dry run, leaving only run metadata
+	Entries []TraceEntry `json:"entries,omitempty"`
 }
 
-// WriteTrace writes the trace.json file (stub for MVP)
-func WriteTrace(outDir, suiteName string, results []suite.Result) error {
+// WriteTrace writes the trace.json file
+func WriteTrace(outDir string, info RunInfo, results []suite.Result) error {
 	path := filepath.Join(outDir, "trace.json")
 
-	now := time.Now().Format(time.RFC3339)
 	trace := Trace{
-		SuiteName:   suiteName,
-		Mode:        "fixtures",
-		StartedAt:   now,
-		CompletedAt: now,
-		Entries:     make([]TraceEntry, 0, len(results)),
+		SuiteName:   info.SuiteName,
+		Mode:        info.Mode,
+		Provider:    info.Provider,
+		Model:       info.Model,
+		Targets:     info.Targets,
+		ReplayMode:  info.Replay,
+		Shard:       info.Shard,
+		StartedAt:   info.StartedAt.Format(time.RFC3339),
+		CompletedAt: info.CompletedAt.Format(time.RFC3339),
+
+		BudgetMillicents:    info.BudgetMillicents,
+		BudgetExceeded:      info.BudgetExceeded,
+		FinalCostMillicents: info.CostMillicents,
+		Interrupted:         info.Interrupted,
+		DryRun:              info.DryRun,
+		CaseCount:           len(results),
+		MinScore:            info.MinScore,
+	}
+	if score, ok := suite.SuiteScore(results); ok && !info.DryRun {
+		trace.SuiteScore = &score
 	}
 
 	for _, r := range results {
+		if info.DryRun {
+			break
+		}
 		entry := TraceEntry{
 			CaseID:      r.ID,
-			Mode:        "fixtures",
-			StartedAt:   now,
-			CompletedAt: now,
+			Target:      r.Target,
+			Mode:        info.Mode,
+			StartedAt:   trace.StartedAt,
+			CompletedAt: trace.CompletedAt,
+		}
+		if r.Trace != nil {
+			entry.Prompt = r.Trace.Prompt
+			entry.Response = r.Trace.Response
+			entry.LatencyMS = r.Trace.LatencyMS
+			entry.InputTokens = r.Trace.InputTokens
+			entry.OutputTokens = r.Trace.OutputTokens
+			entry.CostMillicents = r.Trace.CostMillicents
+			entry.CacheHit = r.Trace.CacheHit
+			entry.RetryCount = r.Trace.RetryCount
+			entry.StartedAt = r.Trace.StartedAt.Format(time.RFC3339)
+			entry.CompletedAt = r.Trace.CompletedAt.Format(time.RFC3339)
 		}
 		trace.Entries = append(trace.Entries, entry)
 	}
 
+	return writeTrace(path, trace)
+}
+
+// writeTrace writes a trace structure to path
+func writeTrace(path string, trace Trace) error {
 	data, err := json.MarshalIndent(trace, "", "  ")
 	if err != nil {
 		return err
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"prompt-ci/internal/provider"
+)
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// complete calls the provider, retrying transient failures up to
+// opts.Retries times. It returns the response and the number of retries used.
+func complete(ctx context.Context, opts Options, req provider.Request) (*provider.Response, int, error) {
+	retries := 0
+	for {
+		resp, err := opts.Provider.Complete(ctx, req)
+		if err == nil || retries >= opts.Retries || !provider.IsTransient(err) {
+			return resp, retries, err
+		}
+
+		delay, ok := provider.RetryAfter(err)
+		if !ok {
+			delay = backoff(retries)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, retries, ctx.Err()
+		}
+		retries++
+	}
+}
+
+// backoff returns the delay before retry n (0-based): exponential growth
+// from retryBaseDelay, capped at retryMaxDelay, with jitter in [d/2, d]
+func backoff(n int) time.Duration {
+	d := retryBaseDelay << n
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
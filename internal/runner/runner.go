@@ -1,83 +1,581 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"time"
 
+	"prompt-ci/internal/provider"
 	"prompt-ci/internal/suite"
 	"prompt-ci/internal/validate"
 )
 
-// RunSuite runs all cases in the suite and returns results
-// Returns the results and a boolean indicating if any errors occurred
-func RunSuite(s *suite.Suite, fixturesDir string, failFast bool) ([]suite.Result, bool) {
-	var results []suite.Result
-	hasError := false
+// Options controls how a suite is run
+type Options struct {
+	// Provider supplies the response for each case
+	Provider provider.Provider
+	// Model is passed through to the provider on every request
+	Model string
+	// FailFast stops the run after the first FAIL or ERROR
+	FailFast bool
+	// Timeout bounds each case, including provider call and validators
+	// (0 means no limit)
+	Timeout time.Duration
+	// Retries is the number of extra attempts for transient provider errors
+	Retries int
+	// BudgetMillicents stops the run once cumulative cost exceeds it
+	// (0 means unlimited)
+	BudgetMillicents int
+	// SpentMillicents is cost already incurred earlier in the run (e.g. by
+	// another target) that counts toward BudgetMillicents
+	SpentMillicents int
+	// Parallel is the number of cases run concurrently (minimum 1)
+	Parallel int
+	// Samples is how many times each case runs unless the case sets its own
+	Samples int
+	// Filter selects the cases to run; the rest are SKIP (nil runs all)
+	Filter *suite.Filter
+}
+
+// Outcome is the result of running a suite
+type Outcome struct {
+	Results []suite.Result
+	// HasError is true if any case ended in ERROR
+	HasError bool
+	// CostMillicents is the cumulative provider cost of the run
+	CostMillicents int
+	// BudgetExceeded is true if the run stopped because of the budget
+	BudgetExceeded bool
+	// Interrupted is true if ctx was cancelled (e.g. by SIGINT) before all
+	// cases finished
+	Interrupted bool
+}
+
+const (
+	// BudgetSkipReason is the skip message for cases not run after the budget ran out
+	BudgetSkipReason = "Budget exceeded before execution"
+	// InterruptedSkipReason is the skip message for cases cut short by an interrupt
+	InterruptedSkipReason = "Run interrupted"
+	// DryRunSkipReason is the skip message for every case in a --dry-run
+	DryRunSkipReason = "Dry run"
+)
 
+// SkipAll returns every case as SKIP with reason, or with the filter's
+// reason for unselected cases
+func SkipAll(s *suite.Suite, filter *suite.Filter, reason string) Outcome {
+	var out Outcome
 	for _, c := range s.Cases {
-		result := runCase(c, s, fixturesDir)
-		results = append(results, result)
+		caseReason := reason
+		if selected, filtered := filter.Match(c); !selected {
+			caseReason = filtered
+		}
+		out.Results = append(out.Results, skippedResult(c, caseReason))
+	}
+	return out
+}
 
-		if result.Status == suite.StatusError {
-			hasError = true
+// DryRun resolves every case's prompt without calling a provider and
+// returns each case as SKIP
+func DryRun(s *suite.Suite, filter *suite.Filter) Outcome {
+	out := SkipAll(s, filter, DryRunSkipReason)
+	for i, c := range s.Cases {
+		out.Results[i].Trace = &suite.CaseTrace{Prompt: c.Transcript()}
+	}
+	return out
+}
+
+// RunSuite runs all cases in the suite and returns the outcome
+// Cases run on up to opts.Parallel workers; results are always returned in
+// suite order. A budget overrun, --fail-fast or cancelling ctx stops
+// in-flight cases.
+func RunSuite(ctx context.Context, s *suite.Suite, opts Options) Outcome {
+	workers := opts.Parallel
+	if limiter, ok := opts.Provider.(provider.ConcurrencyLimiter); ok {
+		if limit := limiter.MaxConcurrency(); limit > 0 && workers > limit {
+			workers = limit
 		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		out       Outcome
+		failed    bool
+		completed = make([]*suite.Result, len(s.Cases))
+		jobs      = make(chan int)
+		wg        sync.WaitGroup
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if runCtx.Err() != nil {
+					continue
+				}
+				result, ok := runCaseSamples(runCtx, s.Cases[i], s, opts)
 
-		if failFast && (result.Status == suite.StatusFail || result.Status == suite.StatusError) {
+				mu.Lock()
+				// A case cancelled by a budget overrun or --fail-fast is
+				// dropped, but what it spent before the cancel still counts
+				if result.Metrics != nil && result.Metrics.Cost != nil {
+					out.CostMillicents += *result.Metrics.Cost
+				}
+				if !ok {
+					mu.Unlock()
+					continue
+				}
+				completed[i] = &result
+				if opts.BudgetMillicents > 0 && opts.SpentMillicents+out.CostMillicents > opts.BudgetMillicents {
+					out.BudgetExceeded = true
+					cancel()
+				}
+				if opts.FailFast && (result.Status == suite.StatusFail || result.Status == suite.StatusError) {
+					failed = true
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i, c := range s.Cases {
+		if runCtx.Err() != nil {
 			break
 		}
+		if selected, _ := opts.Filter.Match(c); !selected {
+			continue
+		}
+		select {
+		case jobs <- i:
+		case <-runCtx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Assemble results in suite order. Cases that never ran are SKIP after a
+	// budget overrun or an interrupt, and omitted after --fail-fast.
+	// Cases excluded by the filter are always SKIP.
+	out.Interrupted = ctx.Err() != nil
+	for i, c := range s.Cases {
+		selected, filtered := opts.Filter.Match(c)
+		switch {
+		case !selected:
+			out.Results = append(out.Results, skippedResult(c, filtered))
+		case completed[i] != nil:
+			out.Results = append(out.Results, *completed[i])
+			if completed[i].Status == suite.StatusError {
+				out.HasError = true
+			}
+		case out.BudgetExceeded:
+			out.Results = append(out.Results, skippedResult(c, BudgetSkipReason))
+		case out.Interrupted:
+			out.Results = append(out.Results, skippedResult(c, InterruptedSkipReason))
+		case failed:
+			// Not run because of --fail-fast
+		}
+	}
+
+	return out
+}
+
+// skippedResult builds the SKIP result for a case that was not run
+func skippedResult(c suite.Case, reason string) suite.Result {
+	return suite.Result{
+		ID:         c.ID,
+		Status:     suite.StatusSkip,
+		Validator:  getValidatorType(c),
+		SkipReason: reason,
+	}
+}
+
+// runCaseSamples runs a case once per sample and derives its status from
+// the fraction of passing samples. Each sample gets its own timeout.
+func runCaseSamples(ctx context.Context, c suite.Case, s *suite.Suite, opts Options) (suite.Result, bool) {
+	n := c.Samples
+	if n == 0 {
+		n = opts.Samples
+	}
+	if n <= 1 {
+		return runCaseWithTimeout(ctx, c, s, opts)
+	}
+
+	samples := make([]suite.Result, 0, n)
+	for i := 0; i < n; i++ {
+		result, ok := runCaseWithTimeout(ctx, c, s, opts)
+		samples = append(samples, result)
+		if !ok {
+			// Keep the cost of the samples that ran
+			return aggregateSamples(c, samples), false
+		}
+	}
+	return aggregateSamples(c, samples), true
+}
+
+// aggregateSamples combines sample results into one case result. The case
+// passes if at least min_pass_rate of the samples passed, and is ERROR only
+// if every sample errored. Tokens and cost are summed, latency is averaged.
+func aggregateSamples(c suite.Case, samples []suite.Result) suite.Result {
+	minRate := 1.0
+	if c.MinPassRate != nil {
+		minRate = *c.MinPassRate
+	}
+
+	result := suite.Result{
+		ID:        c.ID,
+		Validator: getValidatorType(c),
+	}
+
+	var (
+		passed, errored       int
+		tokens, latency, cost int
+		hasMetrics            bool
+		failures              []string
+		errorCode             string
+		scoreSum              float64
+		scored                int
+	)
+	for i, r := range samples {
+		result.DurationMS += r.DurationMS
+		if r.Score != nil {
+			scoreSum += *r.Score
+			scored++
+		}
+		result.Samples = append(result.Samples, suite.SampleResult{
+			Status:         r.Status,
+			Score:          r.Score,
+			DurationMS:     r.DurationMS,
+			FailureReasons: r.FailureReasons,
+			ErrorCode:      r.ErrorCode,
+		})
+
+		switch r.Status {
+		case suite.StatusPass:
+			passed++
+		case suite.StatusError:
+			errored++
+			if errorCode == "" {
+				errorCode = r.ErrorCode
+			}
+		}
+		for _, f := range r.FailureReasons {
+			failures = append(failures, fmt.Sprintf("sample %d: %s", i+1, f))
+		}
+
+		if r.Metrics != nil {
+			hasMetrics = true
+			tokens += *r.Metrics.Tokens
+			latency += *r.Metrics.Latency
+			cost += *r.Metrics.Cost
+		}
+	}
+
+	// The case score is the mean over samples that produced a reply
+	if scored > 0 {
+		score := scoreSum / float64(scored)
+		result.Score = &score
+	}
+
+	n := len(samples)
+	rate := float64(passed) / float64(n)
+	result.PassRate = &rate
+	required := int(math.Ceil(minRate*float64(n) - 1e-9))
+
+	switch {
+	case errored == n:
+		result.Status = suite.StatusError
+		result.ErrorCode = errorCode
+		result.FailureReasons = failures
+	case passed >= required:
+		result.Status = suite.StatusPass
+	default:
+		result.Status = suite.StatusFail
+		summary := fmt.Sprintf("pass rate %d/%d (%.0f%%) is below min_pass_rate %.0f%%", passed, n, rate*100, minRate*100)
+		result.FailureReasons = append([]string{summary}, failures...)
+	}
+
+	if hasMetrics {
+		latency /= n
+		result.Metrics = &suite.Metrics{Tokens: &tokens, Latency: &latency, Cost: &cost}
+	}
+
+	// The trace keeps the first sample's exchange with usage summed over all samples
+	if first := samples[0].Trace; first != nil {
+		trace := *first
+		trace.InputTokens, trace.OutputTokens, trace.CostMillicents, trace.RetryCount = 0, 0, 0, 0
+		for _, r := range samples {
+			if r.Trace == nil {
+				continue
+			}
+			trace.InputTokens += r.Trace.InputTokens
+			trace.OutputTokens += r.Trace.OutputTokens
+			trace.CostMillicents += r.Trace.CostMillicents
+			trace.RetryCount += r.Trace.RetryCount
+			trace.CompletedAt = r.Trace.CompletedAt
+		}
+		result.Trace = &trace
 	}
 
-	return results, hasError
+	return result
+}
+
+// runCaseWithTimeout runs a case under opts.Timeout. A case that does not
+// finish in time becomes an ERROR tagged PC005; its goroutine is abandoned
+// once the provider call observes the cancelled context.
+// Returns false if ctx itself was cancelled before the case finished; the
+// result then still carries whatever the case spent.
+func runCaseWithTimeout(ctx context.Context, c suite.Case, s *suite.Suite, opts Options) (suite.Result, bool) {
+	if opts.Timeout <= 0 {
+		result := runCase(ctx, c, s, opts)
+		return result, !cancelled(ctx, result)
+	}
+
+	caseCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan suite.Result, 1)
+	go func() {
+		done <- runCase(caseCtx, c, s, opts)
+	}()
+
+	select {
+	case result := <-done:
+		return result, !cancelled(ctx, result)
+	case <-caseCtx.Done():
+		if ctx.Err() != nil {
+			// The provider call observes the cancel, so this does not wait long
+			return <-done, false
+		}
+		return timeoutResult(c, opts.Timeout, start), true
+	}
+}
+
+// cancelled reports whether result is an ERROR caused by ctx being cancelled
+func cancelled(ctx context.Context, result suite.Result) bool {
+	return ctx.Err() != nil && result.Status == suite.StatusError
+}
+
+// timeoutResult builds the ERROR result for a case that exceeded its timeout
+func timeoutResult(c suite.Case, timeout time.Duration, start time.Time) suite.Result {
+	return suite.Result{
+		ID:             c.ID,
+		Status:         suite.StatusError,
+		Validator:      getValidatorType(c),
+		DurationMS:     time.Since(start).Milliseconds(),
+		FailureReasons: []string{fmt.Sprintf("case exceeded timeout of %dms", timeout.Milliseconds())},
+		ErrorCode:      suite.ErrorCodeTimeout,
+		Trace: &suite.CaseTrace{
+			Prompt:      c.Transcript(),
+			StartedAt:   start,
+			CompletedAt: time.Now(),
+		},
+	}
 }
 
 // runCase runs a single test case
-func runCase(c suite.Case, s *suite.Suite, fixturesDir string) suite.Result {
+func runCase(ctx context.Context, c suite.Case, s *suite.Suite, opts Options) suite.Result {
 	start := time.Now()
+	trace := &suite.CaseTrace{StartedAt: start}
 
-	// Load fixture
-	content, err := LoadFixture(fixturesDir, c.ID)
-	if err != nil {
-		return suite.Result{
-			ID:             c.ID,
-			Status:         suite.StatusError,
-			Validator:      getValidatorType(c),
-			DurationMS:     time.Since(start).Milliseconds(),
-			FailureReasons: []string{fmt.Sprintf("fixture error: %v", err)},
+	// A single-prompt case is one step; a multi-turn case has one step per
+	// turn and the case assertions apply to the final reply
+	type step struct {
+		turn       int
+		user       string
+		assertions []suite.Assertion
+	}
+	steps := []step{{assertions: c.Assertions}}
+	if len(c.Turns) > 0 {
+		steps = steps[:0]
+		for i, t := range c.Turns {
+			steps = append(steps, step{turn: i + 1, user: t.User, assertions: t.Assertions})
 		}
+		last := &steps[len(steps)-1]
+		last.assertions = append(append([]suite.Assertion(nil), last.assertions...), c.Assertions...)
 	}
 
-	// Run all assertions
+	history := c.History()
 	var failures []string
-	for _, assertion := range c.Assertions {
-		passed, reason := validate.ValidateAssertion(content, assertion, s)
-		if !passed {
-			failures = append(failures, fmt.Sprintf("[%s] %s", assertion.Type, reason))
+	var checks []suite.AssertionResult
+	var usage provider.Response
+	for _, st := range steps {
+		if st.turn > 0 {
+			history = append(history, suite.Message{Role: suite.RoleUser, Content: st.user})
 		}
-	}
+		req := provider.Request{
+			CaseID: c.ID,
+			Model:  opts.Model,
+			Prompt: history[len(history)-1].Content,
+			Tools:  s.Tools,
+			Turn:   st.turn,
+		}
+		if c.IsConversation() {
+			req.Messages = append([]suite.Message(nil), history...)
+		}
+		trace.Prompt = req.Transcript()
 
-	// For grounding cases, also validate citations
-	caseType := suite.GetCaseType(c.ID)
-	if caseType == suite.CaseTypeGrounding {
-		passed, groundingFailures := validate.ValidateGrounding(content, s)
-		if !passed {
-			for _, f := range groundingFailures {
-				failures = append(failures, fmt.Sprintf("[grounding] %s", f))
+		// Get response from the provider
+		resp, retries, err := complete(ctx, opts, req)
+		trace.RetryCount += retries
+		if err != nil {
+			// Earlier turns of a conversation were already paid for
+			trace.CompletedAt = time.Now()
+			trace.CostMillicents = usage.CostMillicents
+			result := suite.Result{
+				ID:             c.ID,
+				Status:         suite.StatusError,
+				Validator:      getValidatorType(c),
+				DurationMS:     time.Since(start).Milliseconds(),
+				FailureReasons: []string{turnPrefix(st.turn) + fmt.Sprintf("provider error: %v", err)},
+				Metrics:        buildMetrics(&usage),
+				Trace:          trace,
+			}
+			var miss *provider.CacheMissError
+			if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &miss) {
+				result.ErrorCode = suite.ErrorCodeTimeout
 			}
+			return result
+		}
+		content := resp.Render()
+		trace.Response = content
+		trace.CacheHit = resp.CacheHit
+		usage.InputTokens += resp.InputTokens
+		usage.OutputTokens += resp.OutputTokens
+		usage.CostMillicents += resp.CostMillicents
+		usage.LatencyMS += resp.LatencyMS
+
+		replyChecks, replyFailures, checkCost := checkReply(validate.WithPrompt(ctx, trace.Prompt), content, st.assertions, c, s)
+		usage.CostMillicents += checkCost
+		for _, check := range replyChecks {
+			check.Turn = st.turn
+			checks = append(checks, check)
 		}
+		for _, f := range replyFailures {
+			failures = append(failures, turnPrefix(st.turn)+f)
+		}
+		history = append(history, suite.Message{Role: suite.RoleAssistant, Content: content})
 	}
+	trace.InputTokens = usage.InputTokens
+	trace.OutputTokens = usage.OutputTokens
+	trace.CostMillicents = usage.CostMillicents
+	trace.LatencyMS = usage.LatencyMS
 
-	// Determine status
+	// Determine status; with min_score a partial answer can still pass, but an
+	// assertion that could not be evaluated makes the case an error
+	score := suite.Score(checks)
 	status := suite.StatusPass
-	if len(failures) > 0 {
+	var errorCode string
+	switch {
+	case hasCheckError(checks):
+		status = suite.StatusError
+		if ctx.Err() != nil {
+			errorCode = suite.ErrorCodeTimeout
+		}
+	case c.MinScore != nil && score < *c.MinScore:
+		status = suite.StatusFail
+		summary := fmt.Sprintf("score %.2f is below min_score %.2f", score, *c.MinScore)
+		failures = append([]string{summary}, failures...)
+	case c.MinScore != nil:
+		failures = nil
+	case len(failures) > 0:
 		status = suite.StatusFail
 	}
 
+	trace.CompletedAt = time.Now()
 	return suite.Result{
 		ID:             c.ID,
 		Status:         status,
+		ErrorCode:      errorCode,
 		Validator:      getValidatorType(c),
 		DurationMS:     time.Since(start).Milliseconds(),
 		FailureReasons: failures,
+		Metrics:        buildMetrics(&usage),
+		Score:          &score,
+		Assertions:     checks,
+		Trace:          trace,
+	}
+}
+
+// checkReply runs the assertions, and the citation checks for grounding
+// cases, against one assistant reply. It returns one result per assertion,
+// the failure messages and what the assertions cost (e.g. judge calls).
+func checkReply(ctx context.Context, content string, assertions []suite.Assertion, c suite.Case, s *suite.Suite) ([]suite.AssertionResult, []string, int) {
+	var checks []suite.AssertionResult
+	var failures []string
+	var cost int
+	for _, assertion := range assertions {
+		v := validate.ValidateAssertion(ctx, content, assertion, s)
+		cost += v.CostMillicents
+		check := v.Result(assertion)
+		if !check.Passed {
+			failures = append(failures, fmt.Sprintf("[%s] %s", assertion.Type, check.Message))
+		}
+		checks = append(checks, check)
+	}
+
+	// For grounding cases, also validate citations
+	caseType := suite.GetCaseType(c.ID)
+	if caseType == suite.CaseTypeGrounding {
+		passed, groundingFailures := validate.ValidateGrounding(content, s)
+		check := suite.AssertionResult{
+			Type:   "grounding",
+			Weight: suite.DefaultAssertionWeight,
+			Passed: passed,
+		}
+		if !passed {
+			check.Message = strings.Join(groundingFailures, "; ")
+			for _, f := range groundingFailures {
+				failures = append(failures, fmt.Sprintf("[grounding] %s", f))
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, failures, cost
+}
+
+// hasCheckError reports whether any assertion could not be evaluated
+func hasCheckError(checks []suite.AssertionResult) bool {
+	for _, check := range checks {
+		if check.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// turnPrefix labels failures of multi-turn cases with their turn
+func turnPrefix(turn int) string {
+	if turn == 0 {
+		return ""
+	}
+	return fmt.Sprintf("turn %d: ", turn)
+}
+
+// buildMetrics converts provider usage into result metrics
+// Returns nil when the provider reported no usage (e.g. fixtures)
+func buildMetrics(resp *provider.Response) *suite.Metrics {
+	tokens := resp.InputTokens + resp.OutputTokens
+	latency := int(resp.LatencyMS)
+	cost := resp.CostMillicents
+	if tokens == 0 && latency == 0 {
+		return nil
+	}
+	return &suite.Metrics{
+		Tokens:  &tokens,
+		Latency: &latency,
+		Cost:    &cost,
 	}
 }
 
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"prompt-ci/internal/provider"
+	"prompt-ci/internal/suite"
+)
+
+// sample returns a single-run result with the given status and cost
+func sample(status suite.Status, cost int) suite.Result {
+	tokens, latency := 10, 100
+	r := suite.Result{Status: status, Metrics: &suite.Metrics{Tokens: &tokens, Latency: &latency, Cost: &cost}}
+	switch status {
+	case suite.StatusFail:
+		r.FailureReasons = []string{"[contains] missing"}
+	case suite.StatusError:
+		r.ErrorCode = "PC004"
+		r.FailureReasons = []string{"provider error"}
+	}
+	return r
+}
+
+func TestAggregateSamples(t *testing.T) {
+	zero, half, twoThirds := 0.0, 0.5, 2.0/3
+	pass, fail, errored := suite.StatusPass, suite.StatusFail, suite.StatusError
+	tests := []struct {
+		name        string
+		minPassRate *float64
+		statuses    []suite.Status
+		want        suite.Status
+		wantRate    float64
+		wantReason  string
+	}{
+		{name: "all pass by default", statuses: []suite.Status{pass, pass, pass}, want: pass, wantRate: 1},
+		{name: "one fail by default", statuses: []suite.Status{pass, fail, pass}, want: fail, wantRate: 2.0 / 3, wantReason: "pass rate 2/3 (67%) is below min_pass_rate 100%"},
+		{name: "explicit zero passes when every sample fails", minPassRate: &zero, statuses: []suite.Status{fail, fail}, want: pass, wantRate: 0},
+		{name: "mixed meets the rate", minPassRate: &half, statuses: []suite.Status{pass, fail, pass, fail}, want: pass, wantRate: 0.5},
+		{name: "mixed meets a repeating rate", minPassRate: &twoThirds, statuses: []suite.Status{pass, fail, pass}, want: pass, wantRate: 2.0 / 3},
+		{name: "mixed misses the rate", minPassRate: &half, statuses: []suite.Status{pass, fail, errored, fail}, want: fail, wantRate: 0.25, wantReason: "sample 3: provider error"},
+		{name: "all samples error", minPassRate: &zero, statuses: []suite.Status{errored, errored}, want: errored, wantRate: 0, wantReason: "sample 2: provider error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var samples []suite.Result
+			for _, s := range tt.statuses {
+				samples = append(samples, sample(s, 5))
+			}
+			c := suite.Case{ID: "c", MinPassRate: tt.minPassRate}
+
+			got := aggregateSamples(c, samples)
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s (reasons %v)", got.Status, tt.want, got.FailureReasons)
+			}
+			if got.PassRate == nil || *got.PassRate != tt.wantRate {
+				t.Errorf("pass rate = %v, want %v", got.PassRate, tt.wantRate)
+			}
+			if tt.wantReason != "" && !strings.Contains(strings.Join(got.FailureReasons, "\n"), tt.wantReason) {
+				t.Errorf("reasons = %v, want one mentioning %q", got.FailureReasons, tt.wantReason)
+			}
+			if tt.want == errored && got.ErrorCode != "PC004" {
+				t.Errorf("error code = %q, want PC004", got.ErrorCode)
+			}
+			if len(got.Samples) != len(samples) {
+				t.Errorf("got %d samples, want %d", len(got.Samples), len(samples))
+			}
+			if got.Metrics == nil || *got.Metrics.Cost != 5*len(samples) || *got.Metrics.Latency != 100 {
+				t.Errorf("metrics = %+v, want summed cost and mean latency", got.Metrics)
+			}
+		})
+	}
+}
+
+// budgetProvider charges 100 millicents per reply. "hello" replies once the
+// first turn of the conversation case has been answered, and "turn 2" only
+// ends when its request is cancelled.
+type budgetProvider struct {
+	firstTurn chan struct{}
+}
+
+func (p *budgetProvider) Name() string { return "budget" }
+
+func (p *budgetProvider) Complete(ctx context.Context, req provider.Request) (*provider.Response, error) {
+	switch req.Prompt {
+	case "hello":
+		<-p.firstTurn
+	case "turn 1":
+		close(p.firstTurn)
+	case "turn 2":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &provider.Response{Content: "ok", InputTokens: 10, CostMillicents: 100}, nil
+}
+
+func TestRunSuiteBudgetCountsCancelledCases(t *testing.T) {
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		t.Run(timeout.String(), func(t *testing.T) {
+			s := &suite.Suite{Cases: []suite.Case{
+				{ID: "single", Prompt: "hello"},
+				{ID: "conversation", Turns: []suite.Turn{{User: "turn 1"}, {User: "turn 2"}}},
+			}}
+			opts := Options{
+				Provider:         &budgetProvider{firstTurn: make(chan struct{})},
+				BudgetMillicents: 50,
+				Parallel:         2,
+				Timeout:          timeout,
+			}
+
+			out := RunSuite(context.Background(), s, opts)
+			if !out.BudgetExceeded {
+				t.Fatal("budget was not exceeded")
+			}
+			// The cancelled conversation had already paid for its first turn
+			if out.CostMillicents != 200 {
+				t.Errorf("cost = %d, want 200", out.CostMillicents)
+			}
+			if got := out.Results[1]; got.Status != suite.StatusSkip || got.SkipReason != BudgetSkipReason {
+				t.Errorf("cancelled case = %s %q, want SKIP %q", got.Status, got.SkipReason, BudgetSkipReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package suite
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// placeholderPattern matches {{name}} placeholders in case templates
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// maxCaseIDLength mirrors the {1,32} bound of the case ID pattern
+const maxCaseIDLength = 32
+
+// expandCases replaces every case that declares vars or a matrix with one
+// concrete case per variable set, in place and in order
+func expandCases(cases []Case) ([]Case, error) {
+	var expanded []Case
+	for _, c := range cases {
+		if len(c.Vars) == 0 && len(c.Matrix) == 0 {
+			expanded = append(expanded, c)
+			continue
+		}
+
+		sets, err := variableSets(c)
+		if err != nil {
+			return nil, err
+		}
+		ids := generateIDs(c.ID, sets)
+		for i, vars := range sets {
+			concrete, err := instantiate(c, ids[i], vars)
+			if err != nil {
+				return nil, err
+			}
+			expanded = append(expanded, concrete)
+		}
+	}
+	return expanded, nil
+}
+
+// variableSets returns the vars table, or the cartesian product of the
+// matrix with keys taken in sorted order
+func variableSets(c Case) ([]map[string]interface{}, error) {
+	if len(c.Vars) > 0 && len(c.Matrix) > 0 {
+		return nil, fmt.Errorf("case '%s': vars and matrix cannot be combined", c.ID)
+	}
+	if len(c.Vars) > 0 {
+		return c.Vars, nil
+	}
+
+	keys := make([]string, 0, len(c.Matrix))
+	for k, values := range c.Matrix {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("case '%s': matrix variable '%s' has no values", c.ID, k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sets := []map[string]interface{}{{}}
+	for _, k := range keys {
+		var next []map[string]interface{}
+		for _, set := range sets {
+			for _, v := range c.Matrix[k] {
+				combined := make(map[string]interface{}, len(set)+1)
+				for name, value := range set {
+					combined[name] = value
+				}
+				combined[k] = v
+				next = append(next, combined)
+			}
+		}
+		sets = next
+	}
+	return sets, nil
+}
+
+// generateIDs builds "{id}_{values}" IDs from the variable values, falling
+// back to "{id}_{n}" when that would be empty, too long or not unique.
+// The base ID is shortened if needed so every ID fits ^[a-z0-9_]{1,32}$.
+func generateIDs(base string, sets []map[string]interface{}) []string {
+	ids := make([]string, len(sets))
+	seen := make(map[string]bool)
+	for i, vars := range sets {
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var parts []string
+		for _, k := range keys {
+			if slug := slugify(fmt.Sprint(vars[k])); slug != "" {
+				parts = append(parts, slug)
+			}
+		}
+
+		id := base + "_" + strings.Join(parts, "_")
+		if len(parts) == 0 || len(id) > maxCaseIDLength || seen[id] {
+			// Number the case by position, counting up past ids already
+			// taken (e.g. a value that slugifies to "2")
+			for n := i + 1; ; n++ {
+				suffix := "_" + strconv.Itoa(n)
+				prefix := base
+				if len(prefix)+len(suffix) > maxCaseIDLength {
+					prefix = prefix[:maxCaseIDLength-len(suffix)]
+				}
+				if id = prefix + suffix; !seen[id] {
+					break
+				}
+			}
+		}
+		seen[id] = true
+		ids[i] = id
+	}
+	return ids
+}
+
+// slugify lowercases s and collapses anything outside [a-z0-9] to "_"
+func slugify(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// instantiate returns a copy of the template case with vars substituted into
+// the prompt, messages, turns and assertion expected values (including
+// those of nested assertions)
+func instantiate(c Case, id string, vars map[string]interface{}) (Case, error) {
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	sub := func(s string) string {
+		out, err := substituteString(s, vars)
+		keep(err)
+		return out
+	}
+	var subAssertions func(assertions []Assertion) []Assertion
+	subAssertions = func(assertions []Assertion) []Assertion {
+		if assertions == nil {
+			return nil
+		}
+		out := make([]Assertion, 0, len(assertions))
+		for _, a := range assertions {
+			expected, err := substituteValue(a.Expected, vars)
+			keep(err)
+			a.Expected = expected
+			if a.Not != nil {
+				a.Not = &subAssertions([]Assertion{*a.Not})[0]
+			}
+			a.AllOf = subAssertions(a.AllOf)
+			a.AnyOf = subAssertions(a.AnyOf)
+			a.NoneOf = subAssertions(a.NoneOf)
+			out = append(out, a)
+		}
+		return out
+	}
+
+	concrete := c
+	concrete.ID = id
+	concrete.Vars = nil
+	concrete.Matrix = nil
+	concrete.Prompt = sub(c.Prompt)
+	concrete.Assertions = subAssertions(c.Assertions)
+
+	concrete.Messages = nil
+	for _, m := range c.Messages {
+		concrete.Messages = append(concrete.Messages, Message{Role: m.Role, Content: sub(m.Content)})
+	}
+	concrete.Turns = nil
+	for _, t := range c.Turns {
+		concrete.Turns = append(concrete.Turns, Turn{User: sub(t.User), Assertions: subAssertions(t.Assertions)})
+	}
+
+	if firstErr != nil {
+		return Case{}, fmt.Errorf("case '%s': %w", c.ID, firstErr)
+	}
+	return concrete, nil
+}
+
+// substituteValue substitutes vars into every string inside v. A string that
+// is exactly one placeholder takes the variable's value with its YAML type,
+// so `const: "{{pr}}"` can become the integer 123.
+func substituteValue(v interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case string:
+		if m := placeholderPattern.FindStringSubmatch(val); m != nil && m[0] == val {
+			value, ok := vars[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("undefined variable '%s'", m[1])
+			}
+			return value, nil
+		}
+		return substituteString(val, vars)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			sub, err := substituteValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = sub
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			sub, err := substituteValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = sub
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// substituteString replaces {{name}} placeholders in s with their values
+func substituteString(s string, vars map[string]interface{}) (string, error) {
+	var err error
+	out := placeholderPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholderPattern.FindStringSubmatch(match)[1]
+		value, ok := vars[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("undefined variable '%s'", name)
+			}
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+	return out, err
+}
@@ -0,0 +1,134 @@
+package suite
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		sets []map[string]interface{}
+		want []string
+	}{
+		{
+			name: "values",
+			base: "greet",
+			sets: []map[string]interface{}{{"lang": "en", "tone": "Formal"}, {"lang": "fr", "tone": "casual"}},
+			want: []string{"greet_en_formal", "greet_fr_casual"},
+		},
+		{
+			name: "too long truncates the base",
+			base: "tool_averyveryverylongcaseidxxyyy",
+			sets: []map[string]interface{}{{"x": "value"}},
+			want: []string{"tool_averyveryverylongcaseidxx_1"},
+		},
+		{
+			name: "no usable value",
+			base: "c",
+			sets: []map[string]interface{}{{"x": "!!"}, {"x": ""}},
+			want: []string{"c_1", "c_2"},
+		},
+		{
+			name: "duplicate values are numbered",
+			base: "c",
+			sets: []map[string]interface{}{{"x": "a b"}, {"x": "a-b"}},
+			want: []string{"c_a_b", "c_2"},
+		},
+		{
+			// The second set falls back to "c_2", which the first already
+			// took, so counting continues to "c_3"
+			name: "fallback skips ids already taken",
+			base: "c",
+			sets: []map[string]interface{}{{"x": 2}, {"x": "?"}},
+			want: []string{"c_2", "c_3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateIDs(tt.base, tt.sets)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("generateIDs = %v, want %v", got, tt.want)
+			}
+			for _, id := range got {
+				if len(id) > maxCaseIDLength {
+					t.Errorf("%q is longer than %d", id, maxCaseIDLength)
+				}
+			}
+		})
+	}
+}
+
+func TestSubstituteValue(t *testing.T) {
+	vars := map[string]interface{}{"x": 1, "name": "Ada", "pr": 123}
+	tests := []struct {
+		name    string
+		in      interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{name: "whole string keeps the type", in: "{{x}}", want: 1},
+		{name: "whole string with spaces", in: "{{ pr }}", want: 123},
+		{name: "embedded placeholder is formatted", in: "PR #{{pr}}", want: "PR #123"},
+		{name: "plain string", in: "hello", want: "hello"},
+		{name: "non-string", in: 4.5, want: 4.5},
+		{
+			name: "nested",
+			in:   map[string]interface{}{"const": "{{pr}}", "items": []interface{}{"hi {{name}}", "{{x}}"}},
+			want: map[string]interface{}{"const": 123, "items": []interface{}{"hi Ada", 1}},
+		},
+		{name: "undefined whole string", in: "{{missing}}", wantErr: "undefined variable 'missing'"},
+		{name: "undefined embedded", in: []interface{}{"a {{missing}}"}, wantErr: "undefined variable 'missing'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := substituteValue(tt.in, vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("substituteValue: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("substituteValue = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCasesMatrixKeepsTypes(t *testing.T) {
+	template := Case{
+		ID:     "count",
+		Prompt: "Count to {{x}}",
+		Matrix: map[string][]interface{}{"x": {1, 2}},
+		Assertions: []Assertion{
+			{Type: "equals", Expected: "{{x}}"},
+			{Type: "all_of", AllOf: []Assertion{{Type: "contains", Expected: "up to {{x}}"}}},
+		},
+	}
+	cases, err := expandCases([]Case{template})
+	if err != nil {
+		t.Fatalf("expandCases: %v", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("got %d cases, want 2", len(cases))
+	}
+	c := cases[0]
+	if c.ID != "count_1" || c.Prompt != "Count to 1" || c.Matrix != nil {
+		t.Errorf("case = %s %q matrix %v", c.ID, c.Prompt, c.Matrix)
+	}
+	if got, ok := c.Assertions[0].Expected.(int); !ok || got != 1 {
+		t.Errorf("expected = %#v, want the int 1", c.Assertions[0].Expected)
+	}
+	if got := c.Assertions[1].AllOf[0].Expected; got != "up to 1" {
+		t.Errorf("nested expected = %#v, want %q", got, "up to 1")
+	}
+	if template.Assertions[0].Expected != "{{x}}" {
+		t.Errorf("template was modified: %#v", template.Assertions[0].Expected)
+	}
+}
@@ -0,0 +1,109 @@
+package suite
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Filter selects a subset of cases by ID, tag and prompt
+// A case is selected only if it passes every configured criterion
+type Filter struct {
+	// Cases are case IDs or glob patterns (e.g. "grounding_*")
+	Cases []string
+	// Tags selects cases carrying any of these tags
+	Tags []string
+	// ExcludeTags drops cases carrying any of these tags
+	ExcludeTags []string
+	// Grep is matched against the case ID and transcript
+	Grep *regexp.Regexp
+}
+
+// NewFilter builds a filter from CLI flag values
+// Returns nil if no criteria are set, which selects every case
+func NewFilter(cases, tags, excludeTags []string, grep string) (*Filter, error) {
+	if len(cases) == 0 && len(tags) == 0 && len(excludeTags) == 0 && grep == "" {
+		return nil, nil
+	}
+
+	f := &Filter{Cases: cases, Tags: tags, ExcludeTags: excludeTags}
+	for _, pattern := range cases {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --case pattern '%s': %w", pattern, err)
+		}
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep pattern '%s': %w", grep, err)
+		}
+		f.Grep = re
+	}
+	return f, nil
+}
+
+// Match reports whether c is selected; if not, it also returns the skip reason
+// A nil filter selects every case
+func (f *Filter) Match(c Case) (bool, string) {
+	if f == nil {
+		return true, ""
+	}
+
+	if len(f.Cases) > 0 && !matchAnyGlob(f.Cases, c.ID) {
+		return false, "Not selected by --case " + strings.Join(f.Cases, ",")
+	}
+
+	tags := CaseTags(c)
+	for _, tag := range f.ExcludeTags {
+		if containsString(tags, tag) {
+			return false, fmt.Sprintf("Excluded by --exclude-tag %s", tag)
+		}
+	}
+	if len(f.Tags) > 0 {
+		selected := false
+		for _, tag := range f.Tags {
+			if containsString(tags, tag) {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			return false, "Not selected by --tag " + strings.Join(f.Tags, ",")
+		}
+	}
+
+	if f.Grep != nil && !f.Grep.MatchString(c.ID) && !f.Grep.MatchString(c.Transcript()) {
+		return false, fmt.Sprintf("Not selected by --grep %s", f.Grep)
+	}
+
+	return true, ""
+}
+
+// CaseTags returns the case's tags plus its category (grounding, schema
+// or tool), which acts as an implicit tag
+func CaseTags(c Case) []string {
+	category := string(GetCaseType(c.ID))
+	if containsString(c.Tags, category) {
+		return c.Tags
+	}
+	return append(append([]string(nil), c.Tags...), category)
+}
+
+func matchAnyGlob(patterns []string, id string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
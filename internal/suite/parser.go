@@ -24,9 +24,45 @@ func Parse(data []byte) (*Suite, error) {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	// Expand templated cases so callers only see concrete cases
+	cases, err := expandCases(suite.Cases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand case templates: %w", err)
+	}
+	suite.Cases = cases
+
+	// Wrapper assertions take their type from the wrapper key
+	for i := range suite.Cases {
+		c := &suite.Cases[i]
+		inferWrapperTypes(c.Assertions)
+		for j := range c.Turns {
+			inferWrapperTypes(c.Turns[j].Assertions)
+		}
+	}
+
 	return &suite, nil
 }
 
+// inferWrapperTypes sets the type of wrapper assertions (not, all_of,
+// any_of, none_of) that omit it
+func inferWrapperTypes(assertions []Assertion) {
+	for i := range assertions {
+		inferWrapperType(&assertions[i])
+	}
+}
+
+func inferWrapperType(a *Assertion) {
+	if keys := a.wrapperKeys(); a.Type == "" && len(keys) == 1 {
+		a.Type = keys[0]
+	}
+	if a.Not != nil {
+		inferWrapperType(a.Not)
+	}
+	inferWrapperTypes(a.AllOf)
+	inferWrapperTypes(a.AnyOf)
+	inferWrapperTypes(a.NoneOf)
+}
+
 // BuildDocIndex creates a map of doc_id -> chunk_ids for quick lookup
 func BuildDocIndex(suite *Suite) map[string]map[string]bool {
 	index := make(map[string]map[string]bool)
@@ -38,21 +74,3 @@ func BuildDocIndex(suite *Suite) map[string]map[string]bool {
 	}
 	return index
 }
-
-// BuildSchemaIndex creates a map of schema names for quick lookup
-func BuildSchemaIndex(suite *Suite) map[string]bool {
-	index := make(map[string]bool)
-	for name := range suite.Schemas {
-		index[name] = true
-	}
-	return index
-}
-
-// BuildToolIndex creates a map of tool names for quick lookup
-func BuildToolIndex(suite *Suite) map[string]bool {
-	index := make(map[string]bool)
-	for _, tool := range suite.Tools {
-		index[tool.Name] = true
-	}
-	return index
-}
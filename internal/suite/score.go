@@ -0,0 +1,56 @@
+package suite
+
+// Score returns the weighted fraction of passed assertions, in [0, 1]
+// If every weight is zero the score is 1 when all assertions passed, else 0
+func Score(results []AssertionResult) float64 {
+	var total, passed float64
+	allPassed := true
+	for _, r := range results {
+		total += r.Weight
+		passed += r.Weight * r.Credit()
+		if !r.Passed {
+			allPassed = false
+		}
+	}
+	if total == 0 {
+		if allPassed {
+			return 1
+		}
+		return 0
+	}
+	return passed / total
+}
+
+// Credit returns the share of its weight that an assertion earns: the
+// partial score of a group, otherwise 1 if it passed and 0 if not
+func (r AssertionResult) Credit() float64 {
+	if r.Score != nil {
+		return *r.Score
+	}
+	if r.Passed {
+		return 1
+	}
+	return 0
+}
+
+// SuiteScore returns the mean case score over results that were scored;
+// ERROR results count as 0 and SKIP results are ignored. ok is false when
+// no case was scored.
+func SuiteScore(results []Result) (score float64, ok bool) {
+	var sum float64
+	n := 0
+	for _, r := range results {
+		switch {
+		case r.Score != nil:
+			sum += *r.Score
+		case r.Status == StatusError:
+		default:
+			continue
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
@@ -0,0 +1,50 @@
+package suite
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Shard identifies one of Count slices of a suite (Index is 1-based)
+type Shard struct {
+	Index int
+	Count int
+}
+
+// ParseShard parses an "i/n" shard spec such as "2/4"
+func ParseShard(spec string) (Shard, error) {
+	var sh Shard
+	var rest string
+	if n, _ := fmt.Sscanf(spec+"\n", "%d/%d%s", &sh.Index, &sh.Count, &rest); n != 2 {
+		return Shard{}, fmt.Errorf("invalid --shard '%s' (expected i/n, e.g. 1/4)", spec)
+	}
+	if sh.Count < 1 || sh.Index < 1 || sh.Index > sh.Count {
+		return Shard{}, fmt.Errorf("invalid --shard '%s' (i must be between 1 and n)", spec)
+	}
+	return sh, nil
+}
+
+// String formats the shard as "i/n"
+func (sh Shard) String() string {
+	return fmt.Sprintf("%d/%d", sh.Index, sh.Count)
+}
+
+// Contains reports whether the case with the given ID belongs to this shard
+// Cases are assigned by FNV-1a hash of the ID, so the split does not depend
+// on case order and is stable across runs
+func (sh Shard) Contains(caseID string) bool {
+	h := fnv.New32a()
+	h.Write([]byte(caseID))
+	return int(h.Sum32()%uint32(sh.Count)) == sh.Index-1
+}
+
+// Select returns the cases that belong to this shard, in suite order
+func (sh Shard) Select(cases []Case) []Case {
+	var selected []Case
+	for _, c := range cases {
+		if sh.Contains(c.ID) {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
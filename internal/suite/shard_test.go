@@ -0,0 +1,53 @@
+package suite
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	valid := map[string]Shard{
+		"1/1": {Index: 1, Count: 1},
+		"2/4": {Index: 2, Count: 4},
+		"4/4": {Index: 4, Count: 4},
+	}
+	for spec, want := range valid {
+		got, err := ParseShard(spec)
+		if err != nil || got != want {
+			t.Errorf("ParseShard(%q) = %v, %v; want %v", spec, got, err, want)
+		}
+		if got.String() != spec {
+			t.Errorf("String() = %q, want %q", got.String(), spec)
+		}
+	}
+
+	for _, spec := range []string{"", "0/0", "0/2", "3/2", "-1/2", "1/-2", "a/b", "1/2x", "1/2/3", "12", "1-2"} {
+		if sh, err := ParseShard(spec); err == nil {
+			t.Errorf("ParseShard(%q) = %v, want an error", spec, sh)
+		}
+	}
+}
+
+func TestShardsCoverEachCaseOnce(t *testing.T) {
+	var cases []Case
+	for i := 0; i < 200; i++ {
+		cases = append(cases, Case{ID: fmt.Sprintf("case_%d", i)})
+	}
+	for _, count := range []int{1, 2, 3, 7} {
+		seen := make(map[string]int)
+		for i := 1; i <= count; i++ {
+			selected := Shard{Index: i, Count: count}.Select(cases)
+			if count > 1 && (len(selected) == 0 || len(selected) == len(cases)) {
+				t.Errorf("shard %d/%d selected %d of %d cases", i, count, len(selected), len(cases))
+			}
+			for _, c := range selected {
+				seen[c.ID]++
+			}
+		}
+		for _, c := range cases {
+			if seen[c.ID] != 1 {
+				t.Errorf("%d shards: %s selected %d times, want once", count, c.ID, seen[c.ID])
+			}
+		}
+	}
+}
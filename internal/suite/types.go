@@ -1,5 +1,10 @@
 package suite
 
+import (
+	"strings"
+	"time"
+)
+
 // Suite represents the top-level eval suite structure
 type Suite struct {
 	Name       string            `yaml:"suite_name"`
@@ -9,6 +14,10 @@ type Suite struct {
 	Tools      []Tool            `yaml:"tools"`
 	Grounding  GroundingConfig   `yaml:"grounding"`
 	Cases      []Case            `yaml:"cases"`
+
+	// MinScore gates the run on the suite score (mean case score, 0.0-1.0)
+	// instead of on individual case failures
+	MinScore *float64 `yaml:"min_score,omitempty"`
 }
 
 // Doc represents a documentation document with chunks
@@ -48,13 +57,190 @@ type Case struct {
 	ID         string      `yaml:"id"`
 	Prompt     string      `yaml:"prompt"`
 	Assertions []Assertion `yaml:"assertions"`
+	Tags       []string    `yaml:"tags,omitempty"`
+
+	// Messages is the conversation sent before Prompt (system, user and
+	// assistant entries); Prompt, if set, is appended as a user message
+	Messages []Message `yaml:"messages,omitempty"`
+	// Turns continue the conversation one user message at a time; each
+	// turn's assertions apply to the assistant reply to it, and the case
+	// assertions apply to the final reply
+	Turns []Turn `yaml:"turns,omitempty"`
+
+	// Vars and Matrix make the case a template: it expands into one case
+	// per variable set, with {{name}} placeholders substituted into the
+	// prompt, messages, turns and assertion expected values. Vars lists the
+	// sets explicitly; Matrix takes the cartesian product of its value lists.
+	Vars   []map[string]interface{} `yaml:"vars,omitempty"`
+	Matrix map[string][]interface{} `yaml:"matrix,omitempty"`
+
+	// Samples overrides --samples for this case (0 uses the flag)
+	Samples int `yaml:"samples,omitempty"`
+	// MinPassRate is the fraction of samples that must pass, 0.0-1.0
+	// (nil means all samples must pass)
+	MinPassRate *float64 `yaml:"min_pass_rate,omitempty"`
+	// MinScore lets a case pass with a weighted score of at least this
+	// value (0.0-1.0); when unset every assertion must pass
+	MinScore *float64 `yaml:"min_score,omitempty"`
+}
+
+// Message roles accepted in Case.Messages
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is one entry in a conversation
+type Message struct {
+	Role    string `yaml:"role" json:"role"`
+	Content string `yaml:"content" json:"content"`
+}
+
+// Turn is one user message in a multi-turn case
+type Turn struct {
+	User       string      `yaml:"user"`
+	Assertions []Assertion `yaml:"assertions,omitempty"`
+}
+
+// History returns the conversation that precedes the first reply:
+// Messages followed by Prompt as a user message
+func (c Case) History() []Message {
+	history := append([]Message(nil), c.Messages...)
+	if c.Prompt != "" {
+		history = append(history, Message{Role: RoleUser, Content: c.Prompt})
+	}
+	return history
+}
+
+// Transcript renders every user-supplied message of the case, including
+// each turn's user message, one "role: content" entry per line; a
+// single-prompt case renders as its prompt
+func (c Case) Transcript() string {
+	if !c.IsConversation() {
+		return c.Prompt
+	}
+	var b strings.Builder
+	write := func(role, content string) {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(role)
+		b.WriteString(": ")
+		b.WriteString(content)
+	}
+	for _, m := range c.History() {
+		write(m.Role, m.Content)
+	}
+	for _, t := range c.Turns {
+		write(RoleUser, t.User)
+	}
+	return b.String()
+}
+
+// IsConversation reports whether the case uses messages or turns rather
+// than a single prompt
+func (c Case) IsConversation() bool {
+	return len(c.Messages) > 0 || len(c.Turns) > 0
+}
+
+// AllAssertions returns the case assertions followed by every turn's assertions
+func (c Case) AllAssertions() []Assertion {
+	all := append([]Assertion(nil), c.Assertions...)
+	for _, t := range c.Turns {
+		all = append(all, t.Assertions...)
+	}
+	return all
 }
 
 // Assertion represents a test assertion
 type Assertion struct {
 	Type     string      `yaml:"type"`
 	Expected interface{} `yaml:"expected"`
-	Weight   float64     `yaml:"weight,omitempty"`
+	// Weight is the assertion's share of the case score (default 1.0)
+	Weight *float64 `yaml:"weight,omitempty"`
+	// Threshold is the minimum similarity for semantic_similarity (0.0-1.0)
+	Threshold *float64 `yaml:"threshold,omitempty"`
+	// EmbeddingModel switches semantic_similarity from lexical similarity
+	// to an OpenAI-compatible embeddings endpoint
+	EmbeddingModel string `yaml:"embedding_model,omitempty"`
+	// JudgeProvider and JudgeModel select the llm_judge model (defaults:
+	// openai and the provider's default model)
+	JudgeProvider string `yaml:"judge_provider,omitempty"`
+	JudgeModel    string `yaml:"judge_model,omitempty"`
+	// PassingScore is the minimum llm_judge score (0.0-1.0, default 0.7)
+	PassingScore *float64 `yaml:"passing_score,omitempty"`
+	// Not wraps another assertion and passes when that assertion fails
+	Not *Assertion `yaml:"not,omitempty"`
+	// AllOf, AnyOf and NoneOf group assertions that must all pass, at
+	// least one pass, or none pass
+	AllOf  []Assertion `yaml:"all_of,omitempty"`
+	AnyOf  []Assertion `yaml:"any_of,omitempty"`
+	NoneOf []Assertion `yaml:"none_of,omitempty"`
+}
+
+// Types given to assertions written as a wrapper, after the wrapper key
+const (
+	AssertionNot    = "not"
+	AssertionAllOf  = "all_of"
+	AssertionAnyOf  = "any_of"
+	AssertionNoneOf = "none_of"
+)
+
+// Nested returns the assertions wrapped by a, if any
+func (a Assertion) Nested() []Assertion {
+	var nested []Assertion
+	if a.Not != nil {
+		nested = append(nested, *a.Not)
+	}
+	nested = append(nested, a.AllOf...)
+	nested = append(nested, a.AnyOf...)
+	return append(nested, a.NoneOf...)
+}
+
+// Group returns the assertions of an all_of, any_of or none_of group
+func (a Assertion) Group() []Assertion {
+	switch a.Type {
+	case AssertionAllOf:
+		return a.AllOf
+	case AssertionAnyOf:
+		return a.AnyOf
+	case AssertionNoneOf:
+		return a.NoneOf
+	}
+	return nil
+}
+
+// wrapperKeys returns the wrapper keys that are set on a, in YAML order
+func (a Assertion) wrapperKeys() []string {
+	var keys []string
+	if a.Not != nil {
+		keys = append(keys, AssertionNot)
+	}
+	if a.AllOf != nil {
+		keys = append(keys, AssertionAllOf)
+	}
+	if a.AnyOf != nil {
+		keys = append(keys, AssertionAnyOf)
+	}
+	if a.NoneOf != nil {
+		keys = append(keys, AssertionNoneOf)
+	}
+	return keys
+}
+
+// Assertion weight bounds
+const (
+	DefaultAssertionWeight = 1.0
+	MaxAssertionWeight     = 10.0
+)
+
+// EffectiveWeight returns Weight, or DefaultAssertionWeight when unset
+func (a Assertion) EffectiveWeight() float64 {
+	if a.Weight == nil {
+		return DefaultAssertionWeight
+	}
+	return *a.Weight
 }
 
 // ValidatorType represents the type of validator to use
@@ -97,9 +283,72 @@ type Result struct {
 	Validator      string   `json:"validator"`
 	DurationMS     int64    `json:"duration_ms"`
 	FailureReasons []string `json:"failure_reasons,omitempty"`
+	ErrorCode      string   `json:"error_code,omitempty"`
+	SkipReason     string   `json:"skip_reason,omitempty"`
 	Metrics        *Metrics `json:"metrics,omitempty"`
+
+	// Score is the weighted fraction of passed assertions, 0.0-1.0
+	Score      *float64          `json:"score,omitempty"`
+	Assertions []AssertionResult `json:"assertions,omitempty"`
+
+	// Target is the "provider:model" the case ran against in a multi-target run
+	Target string `json:"target,omitempty"`
+
+	// PassRate and Samples are only set when the case ran more than once
+	PassRate *float64       `json:"pass_rate,omitempty"`
+	Samples  []SampleResult `json:"samples,omitempty"`
+
+	// Trace holds execution details written to trace.json only
+	Trace *CaseTrace `json:"-"`
+}
+
+// AssertionResult is the outcome of one assertion against a reply
+// Grounding cases add an implicit "grounding" entry for the citation checks
+type AssertionResult struct {
+	Type    string  `json:"type"`
+	Weight  float64 `json:"weight"`
+	Passed  bool    `json:"passed"`
+	Message string  `json:"message,omitempty"`
+	// Error is set when the assertion could not be evaluated; Message holds
+	// the error and the case becomes ERROR
+	Error bool `json:"error,omitempty"`
+	// Rationale is the judge's explanation for llm_judge assertions
+	Rationale string `json:"rationale,omitempty"`
+	// Score is the partial credit of a group assertion (0.0-1.0); other
+	// assertions earn their full weight only when they pass
+	Score *float64 `json:"score,omitempty"`
+	// Branches are the results of the assertions inside a group
+	Branches []AssertionResult `json:"branches,omitempty"`
+	// Turn is the 1-based turn of a multi-turn case (0 otherwise)
+	Turn int `json:"turn,omitempty"`
+}
+
+// SampleResult is the outcome of one run of a multi-sample case
+type SampleResult struct {
+	Status         Status   `json:"status"`
+	Score          *float64 `json:"score,omitempty"`
+	DurationMS     int64    `json:"duration_ms"`
+	FailureReasons []string `json:"failure_reasons,omitempty"`
+	ErrorCode      string   `json:"error_code,omitempty"`
+}
+
+// CaseTrace records what was sent to and received from the provider for a case
+type CaseTrace struct {
+	Prompt         string
+	Response       string
+	InputTokens    int
+	OutputTokens   int
+	CostMillicents int
+	LatencyMS      int64
+	CacheHit       bool
+	RetryCount     int
+	StartedAt      time.Time
+	CompletedAt    time.Time
 }
 
+// MaxSamples is the upper bound for --samples and a case's samples setting
+const MaxSamples = 100
+
 // Status represents the status of a test case
 type Status string
 
@@ -110,6 +359,11 @@ const (
 	StatusSkip  Status = "SKIP"
 )
 
+// Error codes attached to results
+const (
+	ErrorCodeTimeout = "PC005"
+)
+
 // Metrics represents optional performance metrics
 type Metrics struct {
 	Tokens  *int `json:"tokens,omitempty"`
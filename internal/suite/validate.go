@@ -19,7 +19,9 @@ func (e *ValidationError) Error() string {
 }
 
 // ValidateSuite validates the suite structure and returns any errors
-func ValidateSuite(suite *Suite, fixturesDir string) error {
+// Fixture files are only checked when fixturesDir is non-empty, and only
+// for cases selected by filter (nil selects every case)
+func ValidateSuite(suite *Suite, fixturesDir string, filter *Filter) error {
 	var errors []string
 
 	// Check suite name
@@ -27,6 +29,10 @@ func ValidateSuite(suite *Suite, fixturesDir string) error {
 		errors = append(errors, "suite_name is required")
 	}
 
+	if suite.MinScore != nil && (*suite.MinScore < 0 || *suite.MinScore > 1) {
+		errors = append(errors, "min_score must be between 0.0 and 1.0")
+	}
+
 	// Check cases exist
 	if len(suite.Cases) == 0 {
 		errors = append(errors, "cases array must contain at least one test case")
@@ -34,12 +40,11 @@ func ValidateSuite(suite *Suite, fixturesDir string) error {
 
 	// Build indices for lookups
 	docIndex := BuildDocIndex(suite)
-	schemaIndex := BuildSchemaIndex(suite)
-	toolIndex := BuildToolIndex(suite)
 
 	// Track case IDs for uniqueness
 	caseIDs := make(map[string]bool)
 	caseIDPattern := regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+	tagPattern := regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
 
 	for i, c := range suite.Cases {
 		// Validate case ID format
@@ -53,22 +58,60 @@ func ValidateSuite(suite *Suite, fixturesDir string) error {
 		}
 		caseIDs[c.ID] = true
 
+		// Validate tags
+		for _, tag := range c.Tags {
+			if !tagPattern.MatchString(tag) {
+				errors = append(errors, fmt.Sprintf("case[%d] '%s': tag '%s' does not match pattern ^[a-z0-9_-]{1,32}$", i, c.ID, tag))
+			}
+		}
+
+		// Check sampling settings
+		if c.Samples < 0 || c.Samples > MaxSamples {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s': samples must be between 1 and %d", i, c.ID, MaxSamples))
+		}
+		if c.MinPassRate != nil && (*c.MinPassRate < 0 || *c.MinPassRate > 1) {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s': min_pass_rate must be between 0.0 and 1.0", i, c.ID))
+		}
+		if c.MinScore != nil && (*c.MinScore < 0 || *c.MinScore > 1) {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s': min_score must be between 0.0 and 1.0", i, c.ID))
+		}
+
+		// Check the conversation
+		errors = append(errors, validateConversation(c, i)...)
+
 		// Check assertions exist
-		if len(c.Assertions) == 0 {
+		if len(c.AllAssertions()) == 0 {
 			errors = append(errors, fmt.Sprintf("case[%d] '%s': must have at least one assertion", i, c.ID))
 		}
 
 		// Validate each assertion
 		for j, a := range c.Assertions {
-			if err := validateAssertion(a, i, j, c.ID, schemaIndex, toolIndex); err != nil {
+			if err := validateAssertion(a, i, j, c.ID); err != nil {
 				errors = append(errors, err.Error())
 			}
 		}
+		for k, t := range c.Turns {
+			for j, a := range t.Assertions {
+				if err := validateAssertion(a, i, j, c.ID); err != nil {
+					errors = append(errors, fmt.Sprintf("%v (turn %d)", err, k+1))
+				}
+			}
+		}
 
-		// Check fixture exists
-		fixturePath := GetFixturePath(fixturesDir, c.ID)
-		if _, err := os.Stat(fixturePath); os.IsNotExist(err) {
-			errors = append(errors, fmt.Sprintf("case[%d] '%s': fixture file not found at %s", i, c.ID, fixturePath))
+		// Check fixture exists (one per turn for multi-turn cases)
+		if selected, _ := filter.Match(c); fixturesDir != "" && selected {
+			var fixturePaths []string
+			if len(c.Turns) == 0 {
+				fixturePaths = append(fixturePaths, GetFixturePath(fixturesDir, c.ID))
+			}
+			for k := range c.Turns {
+				fixturePaths = append(fixturePaths, GetTurnFixturePath(fixturesDir, c.ID, k+1))
+			}
+			for _, fixturePath := range fixturePaths {
+				if _, err := os.Stat(fixturePath); os.IsNotExist(err) {
+					errors = append(errors, fmt.Sprintf("case[%d] '%s': fixture file not found at %s", i, c.ID, fixturePath))
+				}
+			}
 		}
 	}
 
@@ -83,9 +126,9 @@ func ValidateSuite(suite *Suite, fixturesDir string) error {
 
 	// Validate json_schema assertions have additionalProperties: false
 	for i, c := range suite.Cases {
-		for j, a := range c.Assertions {
-			if a.Type == "json_schema" {
-				if err := validateSchemaHasAdditionalPropertiesFalse(a.Expected, i, j, c.ID); err != nil {
+		for j, a := range c.AllAssertions() {
+			for _, schema := range jsonSchemaAssertions(a) {
+				if err := validateSchemaHasAdditionalPropertiesFalse(schema.Expected, i, j, c.ID); err != nil {
 					errors = append(errors, err.Error())
 				}
 			}
@@ -99,27 +142,74 @@ func ValidateSuite(suite *Suite, fixturesDir string) error {
 	return nil
 }
 
-func validateAssertion(a Assertion, caseIdx, assertIdx int, caseID string, schemaIndex, toolIndex map[string]bool) error {
-	validTypes := map[string]bool{
-		"exact_match":         true,
-		"contains":            true,
-		"regex":               true,
-		"json_schema":         true,
-		"semantic_similarity": true,
-		"llm_judge":           true,
+// assertionTypes maps each known assertion type to the load-time check of
+// its expected value. The validate package registers every type it can run.
+var assertionTypes = map[string]func(Assertion) error{}
+
+// RegisterAssertionType makes an assertion type valid in suites, with check
+// called on each assertion of that type when the suite is validated
+func RegisterAssertionType(name string, check func(Assertion) error) {
+	assertionTypes[name] = check
+}
+
+func validateAssertion(a Assertion, caseIdx, assertIdx int, caseID string) error {
+	if err := CheckAssertion(a); err != nil {
+		return fmt.Errorf("case[%d] '%s' assertion[%d]: %v", caseIdx, caseID, assertIdx, err)
+	}
+	return nil
+}
+
+// CheckAssertion validates a single assertion against the registered
+// assertion types. Wrapper types check their nested assertions with it.
+func CheckAssertion(a Assertion) error {
+	switch keys := a.wrapperKeys(); {
+	case len(keys) > 1:
+		return fmt.Errorf("%s cannot be combined in one assertion", strings.Join(keys, ", "))
+	case len(keys) == 1 && a.Type != keys[0]:
+		return fmt.Errorf("%s cannot be combined with type '%s'", keys[0], a.Type)
+	}
+
+	check, known := assertionTypes[a.Type]
+	if !known {
+		return fmt.Errorf("unknown type '%s'", a.Type)
 	}
 
-	if !validTypes[a.Type] {
-		return fmt.Errorf("case[%d] '%s' assertion[%d]: unknown type '%s'", caseIdx, caseID, assertIdx, a.Type)
+	if a.Expected == nil && !isWrapperType(a.Type) {
+		return fmt.Errorf("expected is required")
 	}
 
-	if a.Expected == nil {
-		return fmt.Errorf("case[%d] '%s' assertion[%d]: expected is required", caseIdx, caseID, assertIdx)
+	if w := a.EffectiveWeight(); w < 0 || w > MaxAssertionWeight {
+		return fmt.Errorf("weight must be between 0.0 and %.1f, got %g", MaxAssertionWeight, w)
 	}
 
+	if check != nil {
+		return check(a)
+	}
 	return nil
 }
 
+// isWrapperType reports whether assertions of type t nest other assertions
+// instead of taking an expected value
+func isWrapperType(t string) bool {
+	switch t {
+	case AssertionNot, AssertionAllOf, AssertionAnyOf, AssertionNoneOf:
+		return true
+	}
+	return false
+}
+
+// jsonSchemaAssertions returns a and its nested assertions that are json_schema
+func jsonSchemaAssertions(a Assertion) []Assertion {
+	var found []Assertion
+	if a.Type == "json_schema" {
+		found = append(found, a)
+	}
+	for _, nested := range a.Nested() {
+		found = append(found, jsonSchemaAssertions(nested)...)
+	}
+	return found
+}
+
 func validateSchemaHasAdditionalPropertiesFalse(expected interface{}, caseIdx, assertIdx int, caseID string) error {
 	schema, ok := expected.(map[string]interface{})
 	if !ok {
@@ -143,6 +233,55 @@ func validateSchemaHasAdditionalPropertiesFalse(expected interface{}, caseIdx, a
 	return nil
 }
 
+// validateConversation checks the prompt, messages and turns of a case
+func validateConversation(c Case, caseIdx int) []string {
+	var errors []string
+	history := c.History()
+
+	if len(history) == 0 && len(c.Turns) == 0 {
+		errors = append(errors, fmt.Sprintf("case[%d] '%s': prompt, messages or turns is required", caseIdx, c.ID))
+	}
+	for j, m := range c.Messages {
+		switch m.Role {
+		case RoleSystem, RoleUser, RoleAssistant:
+		default:
+			errors = append(errors, fmt.Sprintf("case[%d] '%s' message[%d]: role must be system, user or assistant, got '%s'", caseIdx, c.ID, j, m.Role))
+		}
+		if m.Content == "" {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s' message[%d]: content is required", caseIdx, c.ID, j))
+		}
+	}
+
+	if len(c.Turns) == 0 {
+		if len(history) > 0 && history[len(history)-1].Role != RoleUser {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s': the last message must have role user", caseIdx, c.ID))
+		}
+		return errors
+	}
+
+	if len(history) > 0 && history[len(history)-1].Role == RoleUser {
+		errors = append(errors, fmt.Sprintf("case[%d] '%s': with turns, messages must not end with a user message", caseIdx, c.ID))
+	}
+	for k, t := range c.Turns {
+		if t.User == "" {
+			errors = append(errors, fmt.Sprintf("case[%d] '%s' turn[%d]: user is required", caseIdx, c.ID, k))
+		}
+	}
+	return errors
+}
+
+// GetTurnFixturePath returns the fixture path for the reply to turn n
+// (1-based) of a multi-turn case, e.g. tool_x.turn2.out.json
+// Turn 0 is the single reply of a case without turns
+func GetTurnFixturePath(fixturesDir, caseID string, turn int) string {
+	path := GetFixturePath(fixturesDir, caseID)
+	if turn == 0 {
+		return path
+	}
+	dir, file := filepath.Split(path)
+	return filepath.Join(dir, fmt.Sprintf("%s.turn%d%s", caseID, turn, strings.TrimPrefix(file, caseID)))
+}
+
 // GetFixturePath returns the expected fixture path for a case ID
 func GetFixturePath(fixturesDir, caseID string) string {
 	caseType := GetCaseType(caseID)
@@ -1,10 +1,62 @@
 package validate
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"unicode"
+
+	"prompt-ci/internal/suite"
 )
 
+func init() {
+	Register("contains", containsValidator{})
+	Register("exact_match", exactMatchValidator{})
+}
+
+type containsValidator struct{}
+
+func (containsValidator) Check(a suite.Assertion) error {
+	_, err := expectString(a.Expected)
+	return err
+}
+
+func (containsValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	return verdict(ValidateContains(content, a.Expected))
+}
+
+func (containsValidator) forbiddenMatch(content string, a suite.Assertion) (string, bool) {
+	expected, ok := a.Expected.(string)
+	if !ok {
+		return "", false
+	}
+	offset := strings.Index(content, expected)
+	if offset < 0 {
+		return "", false
+	}
+	return fmt.Sprintf("content contains forbidden string %q at offset %d", truncate(expected, maxMatchLength), offset), true
+}
+
+type exactMatchValidator struct{}
+
+func (exactMatchValidator) Check(a suite.Assertion) error {
+	_, err := expectString(a.Expected)
+	return err
+}
+
+func (exactMatchValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	return verdict(ValidateExactMatch(content, a.Expected))
+}
+
+func (exactMatchValidator) forbiddenMatch(content string, a suite.Assertion) (string, bool) {
+	expected, ok := a.Expected.(string)
+	if !ok || strings.TrimSpace(content) != strings.TrimSpace(expected) {
+		return "", false
+	}
+	offset := len(content) - len(strings.TrimLeftFunc(content, unicode.IsSpace))
+	return fmt.Sprintf("content exactly matches forbidden %q at offset %d", truncate(strings.TrimSpace(expected), maxMatchLength), offset), true
+}
+
 // ValidateContains checks if content contains the expected string
 func ValidateContains(content string, expected interface{}) (bool, string) {
 	expectedStr, ok := expected.(string)
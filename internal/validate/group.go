@@ -0,0 +1,125 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"prompt-ci/internal/suite"
+)
+
+func init() {
+	Register(suite.AssertionAllOf, groupValidator{})
+	Register(suite.AssertionAnyOf, groupValidator{})
+	Register(suite.AssertionNoneOf, groupValidator{})
+}
+
+// groupValidator checks all_of, any_of and none_of groups. Each branch keeps
+// its own weight, and the group earns the weighted credit of its branches
+// as partial score within the group's weight.
+type groupValidator struct{}
+
+func (groupValidator) Check(a suite.Assertion) error {
+	if a.Expected != nil {
+		return fmt.Errorf("%s takes no expected value; set it on the nested assertions", a.Type)
+	}
+	branches := a.Group()
+	if len(branches) == 0 {
+		return fmt.Errorf("%s requires at least one assertion", a.Type)
+	}
+	for i, b := range branches {
+		if err := suite.CheckAssertion(b); err != nil {
+			return fmt.Errorf("%s[%d]: %v", a.Type, i, err)
+		}
+	}
+	return nil
+}
+
+func (groupValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	branches := a.Group()
+	results := make([]suite.AssertionResult, len(branches))
+	var passed, failed, errored []int
+	var cost int
+	for i, b := range branches {
+		branch := ValidateAssertion(ctx, content, b, s)
+		cost += branch.CostMillicents
+		results[i] = branch.Result(b)
+		switch {
+		case results[i].Error:
+			errored = append(errored, i)
+		case results[i].Passed:
+			passed = append(passed, i)
+		default:
+			failed = append(failed, i)
+		}
+	}
+
+	// A branch that could not be evaluated leaves the group undecided; in
+	// particular none_of must not count it as a branch that correctly failed
+	v := Verdict{Branches: results, CostMillicents: cost}
+	if len(errored) > 0 {
+		v.Err = fmt.Errorf("%d of %d branches could not be evaluated: %s", len(errored), len(branches), describeBranches(results, errored))
+		return v
+	}
+	var score float64
+	switch a.Type {
+	case suite.AssertionAllOf:
+		v.Passed = len(failed) == 0
+		score = suite.Score(results)
+		if !v.Passed {
+			v.Message = fmt.Sprintf("%d of %d branches failed: %s", len(failed), len(branches), describeBranches(results, failed))
+		}
+	case suite.AssertionAnyOf:
+		v.Passed = len(passed) > 0
+		score = 1
+		if !v.Passed {
+			score = suite.Score(results)
+			v.Message = "no branch passed: " + describeBranches(results, failed)
+		}
+	case suite.AssertionNoneOf:
+		v.Passed = len(passed) == 0
+		score = 1
+		if !v.Passed {
+			// Credit the branches that correctly failed
+			inverted := make([]suite.AssertionResult, len(results))
+			for i, r := range results {
+				credit := 1 - r.Credit()
+				inverted[i] = suite.AssertionResult{Weight: r.Weight, Passed: !r.Passed, Score: &credit}
+			}
+			score = suite.Score(inverted)
+			v.Message = fmt.Sprintf("%d of %d branches passed: %s", len(passed), len(branches), describeMatches(content, branches, passed))
+		}
+	}
+	v.Score = &score
+	return v
+}
+
+// describeBranches lists the failure messages of the given branches as
+// "[i] type: message", with nested group messages in parentheses
+func describeBranches(results []suite.AssertionResult, indices []int) string {
+	parts := make([]string, 0, len(indices))
+	for _, i := range indices {
+		message := results[i].Message
+		if len(results[i].Branches) > 0 {
+			message = "(" + message + ")"
+		}
+		parts = append(parts, fmt.Sprintf("[%d] %s: %s", i, results[i].Type, message))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeMatches lists branches of a none_of group that passed, quoting
+// the offending match where the validator can locate it
+func describeMatches(content string, branches []suite.Assertion, indices []int) string {
+	parts := make([]string, 0, len(indices))
+	for _, i := range indices {
+		message := "passed"
+		if m, ok := registry[branches[i].Type].(forbiddenMatcher); ok {
+			if found, ok := m.forbiddenMatch(content, branches[i]); ok {
+				message = found
+			}
+		}
+		parts = append(parts, fmt.Sprintf("[%d] %s: %s", i, branches[i].Type, message))
+	}
+	return strings.Join(parts, "; ")
+}
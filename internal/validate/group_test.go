@@ -0,0 +1,118 @@
+package validate
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"prompt-ci/internal/suite"
+)
+
+func contains(expected string) suite.Assertion {
+	return suite.Assertion{Type: "contains", Expected: expected}
+}
+
+func group(groupType string, branches ...suite.Assertion) suite.Assertion {
+	a := suite.Assertion{Type: groupType}
+	switch groupType {
+	case suite.AssertionAllOf:
+		a.AllOf = branches
+	case suite.AssertionAnyOf:
+		a.AnyOf = branches
+	case suite.AssertionNoneOf:
+		a.NoneOf = branches
+	}
+	return a
+}
+
+func TestGroups(t *testing.T) {
+	const content = "alpha beta"
+	heavy := 3.0
+	weighted := contains("gamma")
+	weighted.Weight = &heavy
+	priced := suite.Assertion{Type: "priced", Expected: "alpha"}
+	pricedError := suite.Assertion{Type: "priced", Expected: "error"}
+
+	tests := []struct {
+		name     string
+		a        suite.Assertion
+		passed   bool
+		score    float64
+		wantErr  string
+		wantMsg  string
+		wantCost int
+	}{
+		{name: "all_of passes", a: group("all_of", contains("alpha"), contains("beta")), passed: true, score: 1},
+		{name: "all_of partial credit", a: group("all_of", contains("alpha"), weighted), score: 0.25, wantMsg: "1 of 2 branches failed: [1] contains"},
+		{name: "any_of one branch passes", a: group("any_of", contains("gamma"), contains("beta")), passed: true, score: 1},
+		{name: "any_of none pass", a: group("any_of", contains("gamma"), contains("delta")), score: 0, wantMsg: "no branch passed: [0] contains"},
+		{name: "none_of passes", a: group("none_of", contains("gamma"), contains("delta")), passed: true, score: 1},
+		{name: "none_of credits branches that failed", a: group("none_of", contains("alpha"), weighted), score: 0.75, wantMsg: `[0] contains: content contains forbidden string "alpha" at offset 0`},
+		{
+			name:    "nested failure",
+			a:       group("all_of", contains("alpha"), group("any_of", contains("gamma"), contains("delta"))),
+			score:   0.5,
+			wantMsg: "[1] any_of: (no branch passed: [0] contains",
+		},
+		{
+			name:   "nested not",
+			a:      group("any_of", suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "contains", Expected: "alpha"}}, contains("beta")),
+			passed: true,
+			score:  1,
+		},
+		{name: "costs are summed", a: group("all_of", priced, group("none_of", priced)), score: 0.5, wantCost: 2 * pricedCost},
+		{name: "errored branch errors the group", a: group("any_of", contains("alpha"), pricedError), wantErr: "1 of 2 branches could not be evaluated: [1] priced: endpoint unavailable", wantCost: pricedCost},
+		{name: "none_of does not count an error as a failure", a: group("none_of", pricedError), wantErr: "could not be evaluated", wantCost: pricedCost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := suite.CheckAssertion(tt.a); err != nil {
+				t.Fatalf("CheckAssertion: %v", err)
+			}
+			v := ValidateAssertion(context.Background(), content, tt.a, &suite.Suite{})
+			if v.CostMillicents != tt.wantCost {
+				t.Errorf("cost = %d, want %d", v.CostMillicents, tt.wantCost)
+			}
+			if tt.wantErr != "" {
+				if v.Err == nil || !strings.Contains(v.Err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want it to mention %q", v.Err, tt.wantErr)
+				}
+				return
+			}
+			if v.Err != nil || v.Passed != tt.passed {
+				t.Fatalf("verdict = %+v, want passed %v", v, tt.passed)
+			}
+			if v.Score == nil || math.Abs(*v.Score-tt.score) > 1e-9 {
+				t.Errorf("score = %v, want %g", v.Score, tt.score)
+			}
+			if !strings.Contains(v.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to mention %q", v.Message, tt.wantMsg)
+			}
+			if len(v.Branches) != len(tt.a.Group()) {
+				t.Errorf("got %d branch results, want %d", len(v.Branches), len(tt.a.Group()))
+			}
+		})
+	}
+}
+
+func TestGroupCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		a    suite.Assertion
+		want string
+	}{
+		{name: "empty any_of", a: suite.Assertion{Type: "any_of", AnyOf: []suite.Assertion{}}, want: "any_of requires at least one assertion"},
+		{name: "empty all_of", a: suite.Assertion{Type: "all_of"}, want: "all_of requires at least one assertion"},
+		{name: "expected on the group", a: suite.Assertion{Type: "none_of", Expected: "x", NoneOf: []suite.Assertion{contains("x")}}, want: "takes no expected value"},
+		{name: "invalid branch", a: group("all_of", contains("x"), suite.Assertion{Type: "regex", Expected: "("}), want: "all_of[1]:"},
+		{name: "nested empty group", a: group("any_of", contains("x"), group("none_of")), want: "any_of[1]: none_of requires at least one assertion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := suite.CheckAssertion(tt.a); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,13 +1,34 @@
 package validate
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"prompt-ci/internal/suite"
 )
 
+func init() {
+	Register("json_schema", jsonSchemaValidator{})
+}
+
+type jsonSchemaValidator struct{}
+
+func (jsonSchemaValidator) Check(a suite.Assertion) error {
+	if _, ok := a.Expected.(map[string]interface{}); !ok {
+		return fmt.Errorf("expected must be a JSON schema object, got %T", a.Expected)
+	}
+	_, err := compileSchema(a.Expected)
+	return err
+}
+
+func (jsonSchemaValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	return verdict(ValidateJSONSchema(content, a.Expected))
+}
+
 // ValidateJSONSchema validates content against a JSON schema
 func ValidateJSONSchema(content string, expected interface{}) (bool, string) {
 	// First, try to extract JSON from the content
@@ -22,29 +43,36 @@ func ValidateJSONSchema(content string, expected interface{}) (bool, string) {
 		return false, fmt.Sprintf("content is not valid JSON: %v", err)
 	}
 
-	// Convert expected schema to JSON
+	schema, err := compileSchema(expected)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	// Validate content against schema
+	if err := schema.Validate(contentData); err != nil {
+		return false, fmt.Sprintf("JSON schema validation failed: %v", err)
+	}
+
+	return true, ""
+}
+
+// compileSchema compiles an expected value as a JSON schema
+func compileSchema(expected interface{}) (*jsonschema.Schema, error) {
 	schemaBytes, err := json.Marshal(expected)
 	if err != nil {
-		return false, fmt.Sprintf("failed to marshal schema: %v", err)
+		return nil, fmt.Errorf("failed to marshal schema: %v", err)
 	}
 
-	// Compile the schema
 	compiler := jsonschema.NewCompiler()
 	if err := compiler.AddResource("schema.json", strings.NewReader(string(schemaBytes))); err != nil {
-		return false, fmt.Sprintf("failed to add schema resource: %v", err)
+		return nil, fmt.Errorf("failed to add schema resource: %v", err)
 	}
 
 	schema, err := compiler.Compile("schema.json")
 	if err != nil {
-		return false, fmt.Sprintf("failed to compile schema: %v", err)
+		return nil, fmt.Errorf("failed to compile schema: %v", err)
 	}
-
-	// Validate content against schema
-	if err := schema.Validate(contentData); err != nil {
-		return false, fmt.Sprintf("JSON schema validation failed: %v", err)
-	}
-
-	return true, ""
+	return schema, nil
 }
 
 // extractJSON attempts to extract a JSON object or array from content
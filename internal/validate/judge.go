@@ -0,0 +1,295 @@
+package validate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"prompt-ci/internal/provider"
+	"prompt-ci/internal/suite"
+)
+
+const (
+	// DefaultJudgeProvider grades llm_judge assertions without judge_provider
+	DefaultJudgeProvider = "openai"
+	// DefaultPassingScore is the llm_judge passing score without passing_score
+	DefaultPassingScore = 0.7
+	// judgeTimeout bounds each call to the judge model
+	judgeTimeout = 60 * time.Second
+)
+
+const judgePromptTemplate = `You are grading a response produced by an AI assistant against a rubric.
+
+Rubric:
+%s
+
+Prompt given to the assistant:
+<<<
+%s
+>>>
+
+Response:
+<<<
+%s
+>>>
+
+Score how well the response satisfies the rubric, from 0.0 (not at all) to 1.0 (fully).
+Reply with only a JSON object of the form {"score": <number>, "rationale": "<one or two sentences>"}.`
+
+// JudgeOptions configures how llm_judge assertions reach their judge
+type JudgeOptions struct {
+	// CacheDir stores verdicts keyed by content hash ("" disables the cache)
+	CacheDir string
+	// Replay answers only from the verdict cache and never calls a judge
+	Replay bool
+	// Models resolves judge models; the built-in table is used when nil
+	Models provider.ModelTable
+}
+
+var (
+	judgeMu      sync.Mutex
+	judgeOptions JudgeOptions
+	judges       = make(map[string]provider.Provider)
+)
+
+// SetJudgeOptions replaces the llm_judge settings for the following runs
+func SetJudgeOptions(o JudgeOptions) {
+	judgeMu.Lock()
+	defer judgeMu.Unlock()
+	judgeOptions = o
+}
+
+// CloseJudges stops judge providers that hold resources (e.g. a local model)
+func CloseJudges() {
+	judgeMu.Lock()
+	defer judgeMu.Unlock()
+	for key, p := range judges {
+		if c, ok := p.(io.Closer); ok {
+			c.Close()
+		}
+		delete(judges, key)
+	}
+}
+
+func init() {
+	Register("llm_judge", judgeValidator{})
+}
+
+type judgeValidator struct{}
+
+func (judgeValidator) Check(a suite.Assertion) error {
+	if _, err := judgeRubric(a.Expected); err != nil {
+		return err
+	}
+	if a.JudgeProvider != "" {
+		known := false
+		for _, name := range provider.Names() {
+			known = known || name == a.JudgeProvider
+		}
+		if !known || a.JudgeProvider == "fixtures" {
+			return fmt.Errorf("unknown judge_provider '%s'", a.JudgeProvider)
+		}
+	}
+	if a.PassingScore != nil && (*a.PassingScore < 0 || *a.PassingScore > 1) {
+		return fmt.Errorf("passing_score must be between 0.0 and 1.0, got %g", *a.PassingScore)
+	}
+	return nil
+}
+
+func (judgeValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	rubric, err := judgeRubric(a.Expected)
+	if err != nil {
+		return verdictError(err)
+	}
+	passing := DefaultPassingScore
+	if a.PassingScore != nil {
+		passing = *a.PassingScore
+	}
+
+	prompt := fmt.Sprintf(judgePromptTemplate, rubric, promptFrom(ctx), content)
+	j, cost, err := judge(ctx, a.JudgeProvider, a.JudgeModel, prompt)
+	if err != nil {
+		v := verdictError(err)
+		v.CostMillicents = cost
+		return v
+	}
+
+	v := Verdict{Passed: j.Score >= passing, Rationale: j.Rationale, CostMillicents: cost}
+	if !v.Passed {
+		v.Message = fmt.Sprintf("judge score %.2f is below passing_score %.2f", j.Score, passing)
+		if j.Rationale != "" {
+			v.Message += ": " + j.Rationale
+		}
+	}
+	return v
+}
+
+// judgeRubric renders expected as the rubric: a string, or a list of
+// criteria that becomes a numbered list
+func judgeRubric(expected interface{}) (string, error) {
+	switch val := expected.(type) {
+	case string:
+		if strings.TrimSpace(val) == "" {
+			return "", fmt.Errorf("expected (the rubric) must not be empty")
+		}
+		return val, nil
+	case []interface{}:
+		if len(val) == 0 {
+			return "", fmt.Errorf("expected (the rubric) must not be empty")
+		}
+		var b strings.Builder
+		for i, item := range val {
+			criterion, ok := item.(string)
+			if !ok {
+				return "", fmt.Errorf("rubric criteria must be strings, got %T", item)
+			}
+			fmt.Fprintf(&b, "%d. %s\n", i+1, criterion)
+		}
+		return strings.TrimSuffix(b.String(), "\n"), nil
+	default:
+		return "", fmt.Errorf("expected must be a rubric string or a list of criteria, got %T", expected)
+	}
+}
+
+// judgment is the structured verdict parsed from the judge's reply
+type judgment struct {
+	Score     float64 `json:"score"`
+	Rationale string  `json:"rationale"`
+}
+
+// judge returns the verdict for prompt, from the cache when possible, and
+// the cost of the judge call (0 on a cache hit)
+func judge(ctx context.Context, providerName, model, prompt string) (judgment, int, error) {
+	judgeMu.Lock()
+	opts := judgeOptions
+	judgeMu.Unlock()
+
+	if providerName == "" {
+		providerName = DefaultJudgeProvider
+	}
+	table := opts.Models
+	if table == nil {
+		var err error
+		if table, err = provider.LoadModelTable(""); err != nil {
+			return judgment{}, 0, err
+		}
+	}
+	model, err := table.Resolve(providerName, model)
+	if err != nil {
+		return judgment{}, 0, err
+	}
+
+	key := judgeCacheKey(providerName, model, prompt)
+	if j, ok := readCachedJudgment(opts.CacheDir, key); ok {
+		return j, 0, nil
+	}
+	if opts.Replay {
+		return judgment{}, 0, fmt.Errorf("Replay mode: no cached verdict from %s:%s", providerName, model)
+	}
+
+	p, err := judgeProvider(providerName, model)
+	if err != nil {
+		return judgment{}, 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, judgeTimeout)
+	defer cancel()
+	resp, err := p.Complete(ctx, provider.Request{CaseID: "llm_judge", Model: model, Prompt: prompt})
+	if err != nil {
+		return judgment{}, 0, fmt.Errorf("judge %s:%s failed: %v", providerName, model, err)
+	}
+
+	// An unparseable verdict was still paid for
+	j, err := parseJudgment(resp.Content)
+	if err != nil {
+		return judgment{}, resp.CostMillicents, err
+	}
+	if err := writeCachedJudgment(opts.CacheDir, key, j); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write verdict cache entry: %v\n", err)
+	}
+	return j, resp.CostMillicents, nil
+}
+
+// judgeProvider returns the shared judge for provider:model, creating it
+// on first use
+func judgeProvider(providerName, model string) (provider.Provider, error) {
+	judgeMu.Lock()
+	defer judgeMu.Unlock()
+
+	key := providerName + ":" + model
+	if p, ok := judges[key]; ok {
+		return p, nil
+	}
+	if err := provider.CheckCredentials(providerName); err != nil {
+		return nil, err
+	}
+	p, err := provider.New(providerName, provider.Config{Model: model})
+	if err != nil {
+		return nil, err
+	}
+	judges[key] = p
+	return p, nil
+}
+
+// parseJudgment extracts the {"score", "rationale"} object from a reply
+func parseJudgment(reply string) (judgment, error) {
+	var raw struct {
+		Score     *float64 `json:"score"`
+		Rationale string   `json:"rationale"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(reply)), &raw); err != nil {
+		return judgment{}, fmt.Errorf("could not parse judge verdict %q: %v", truncate(reply, 200), err)
+	}
+	if raw.Score == nil {
+		return judgment{}, fmt.Errorf("judge verdict has no score: %q", truncate(reply, 200))
+	}
+	if *raw.Score < 0 || *raw.Score > 1 {
+		return judgment{}, fmt.Errorf("judge score %g is outside 0.0-1.0", *raw.Score)
+	}
+	return judgment{Score: *raw.Score, Rationale: strings.TrimSpace(raw.Rationale)}, nil
+}
+
+// judgeCacheKey computes SHA256(provider 0x00 model 0x00 judge prompt), so
+// the same response graded against the same rubric reuses its verdict
+func judgeCacheKey(providerName, model, prompt string) string {
+	h := sha256.Sum256([]byte(providerName + "\x00" + model + "\x00" + prompt))
+	return hex.EncodeToString(h[:])
+}
+
+// cacheEntryPath returns where a verdict or embedding with key is stored
+func cacheEntryPath(dir, key string) string {
+	return filepath.Join(dir, key[:2], key+".json")
+}
+
+func readCachedJudgment(dir, key string) (judgment, bool) {
+	if dir == "" {
+		return judgment{}, false
+	}
+	data, err := os.ReadFile(cacheEntryPath(dir, key))
+	if err != nil {
+		return judgment{}, false
+	}
+	var j judgment
+	if err := json.Unmarshal(data, &j); err != nil {
+		return judgment{}, false
+	}
+	return j, true
+}
+
+func writeCachedJudgment(dir, key string, j judgment) error {
+	if dir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return provider.WriteFileAtomic(cacheEntryPath(dir, key), data)
+}
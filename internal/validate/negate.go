@@ -0,0 +1,83 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+
+	"prompt-ci/internal/suite"
+)
+
+// maxMatchLength limits how much of a forbidden match is quoted
+const maxMatchLength = 80
+
+func init() {
+	Register(suite.AssertionNot, notValidator{})
+	Register("not_contains", notValidator{negates: "contains"})
+	Register("not_regex", notValidator{negates: "regex"})
+}
+
+// forbiddenMatcher is implemented by validators that pass by finding text in
+// the response, so a negation can report what was found and where
+type forbiddenMatcher interface {
+	// forbiddenMatch describes the first match in content, if any
+	forbiddenMatch(content string, a suite.Assertion) (string, bool)
+}
+
+// notValidator passes when the negated assertion fails. With negates set it
+// is a shorthand such as not_contains that negates that type with its own
+// expected value; otherwise it negates the assertion under not:.
+type notValidator struct {
+	negates string
+}
+
+func (v notValidator) negated(a suite.Assertion) (suite.Assertion, error) {
+	if v.negates != "" {
+		return suite.Assertion{Type: v.negates, Expected: a.Expected}, nil
+	}
+	if a.Not == nil {
+		return suite.Assertion{}, fmt.Errorf("not requires a nested assertion")
+	}
+	return *a.Not, nil
+}
+
+func (v notValidator) Check(a suite.Assertion) error {
+	inner, err := v.negated(a)
+	if err != nil {
+		return err
+	}
+	if v.negates == "" && a.Expected != nil {
+		return fmt.Errorf("not takes no expected value; set it on the nested assertion")
+	}
+	if err := suite.CheckAssertion(inner); err != nil {
+		if v.negates != "" {
+			return err
+		}
+		return fmt.Errorf("not: %v", err)
+	}
+	return nil
+}
+
+func (v notValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	inner, err := v.negated(a)
+	if err != nil {
+		return verdictError(err)
+	}
+	// An inner assertion that could not be evaluated is not a failure to flip
+	result := ValidateAssertion(ctx, content, inner, s)
+	if result.Err != nil {
+		return result
+	}
+	negated := verdict(!result.Passed, "")
+	negated.CostMillicents = result.CostMillicents
+	if negated.Passed {
+		return negated
+	}
+
+	negated.Message = fmt.Sprintf("%s assertion passed but is negated", inner.Type)
+	if m, ok := registry[inner.Type].(forbiddenMatcher); ok {
+		if message, found := m.forbiddenMatch(content, inner); found {
+			negated.Message = message
+		}
+	}
+	return negated
+}
@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"prompt-ci/internal/suite"
+)
+
+// pricedCost is what each evaluation of a "priced" assertion costs
+const pricedCost = 7
+
+func init() {
+	Register("priced", pricedValidator{})
+}
+
+// pricedValidator is a contains check that costs pricedCost per evaluation,
+// standing in for the judge and embeddings. Expected "error" makes it fail
+// to evaluate, after spending.
+type pricedValidator struct{}
+
+func (pricedValidator) Check(a suite.Assertion) error {
+	_, err := expectString(a.Expected)
+	return err
+}
+
+func (pricedValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	v := verdict(ValidateContains(content, a.Expected))
+	if a.Expected == "error" {
+		v = verdictError(errors.New("endpoint unavailable"))
+	}
+	v.CostMillicents = pricedCost
+	return v
+}
+
+func TestNot(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        suite.Assertion
+		content  string
+		passed   bool
+		wantErr  string
+		wantMsg  string
+		wantCost int
+	}{
+		{
+			name:    "inner fails",
+			a:       suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "contains", Expected: "secret"}},
+			content: "nothing to see",
+			passed:  true,
+		},
+		{
+			name:    "inner passes",
+			a:       suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "contains", Expected: "secret"}},
+			content: "the secret is out",
+			wantMsg: `forbidden string "secret" at offset 4`,
+		},
+		{
+			name:    "shorthand",
+			a:       suite.Assertion{Type: "not_regex", Expected: `\d+`},
+			content: "no digits",
+			passed:  true,
+		},
+		{
+			name:    "inner error is not inverted",
+			a:       suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "no_such_type", Expected: "x"}},
+			content: "anything",
+			wantErr: "unknown assertion type: no_such_type",
+		},
+		{
+			name:     "cost is carried",
+			a:        suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "priced", Expected: "secret"}},
+			content:  "nothing to see",
+			passed:   true,
+			wantCost: pricedCost,
+		},
+		{
+			name:     "cost is carried on error",
+			a:        suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "priced", Expected: "error"}},
+			content:  "anything",
+			wantErr:  "endpoint unavailable",
+			wantCost: pricedCost,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := ValidateAssertion(context.Background(), tt.content, tt.a, &suite.Suite{})
+			if tt.wantErr != "" {
+				if v.Err == nil || !strings.Contains(v.Err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want it to mention %q", v.Err, tt.wantErr)
+				}
+			} else if v.Err != nil || v.Passed != tt.passed {
+				t.Errorf("verdict = %+v, want passed %v", v, tt.passed)
+			}
+			if !strings.Contains(v.Message, tt.wantMsg) {
+				t.Errorf("message = %q, want it to mention %q", v.Message, tt.wantMsg)
+			}
+			if v.CostMillicents != tt.wantCost {
+				t.Errorf("cost = %d, want %d", v.CostMillicents, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestNotCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		a    suite.Assertion
+		want string
+	}{
+		{name: "missing nested assertion", a: suite.Assertion{Type: "not"}, want: "requires a nested assertion"},
+		{name: "expected on the wrapper", a: suite.Assertion{Type: "not", Expected: "x", Not: &suite.Assertion{Type: "contains", Expected: "x"}}, want: "takes no expected value"},
+		{name: "invalid nested assertion", a: suite.Assertion{Type: "not", Not: &suite.Assertion{Type: "contains"}}, want: "not: expected is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := suite.CheckAssertion(tt.a); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,51 @@
 package validate
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+
+	"prompt-ci/internal/suite"
 )
 
+func init() {
+	Register("regex", regexValidator{})
+}
+
+type regexValidator struct{}
+
+func (regexValidator) Check(a suite.Assertion) error {
+	pattern, ok := a.Expected.(string)
+	if !ok {
+		return fmt.Errorf("expected must be a string pattern, got %T", a.Expected)
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid regex pattern '%s': %v", pattern, err)
+	}
+	return nil
+}
+
+func (regexValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	return verdict(ValidateRegex(content, a.Expected))
+}
+
+func (regexValidator) forbiddenMatch(content string, a suite.Assertion) (string, bool) {
+	pattern, ok := a.Expected.(string)
+	if !ok {
+		return "", false
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", false
+	}
+	loc := re.FindStringIndex(content)
+	if loc == nil {
+		return "", false
+	}
+	match := content[loc[0]:loc[1]]
+	return fmt.Sprintf("content matches forbidden pattern '%s' at offset %d: %q", pattern, loc[0], truncate(match, maxMatchLength)), true
+}
+
 // ValidateRegex checks if content matches the expected regex pattern
 func ValidateRegex(content string, expected interface{}) (bool, string) {
 	pattern, ok := expected.(string)
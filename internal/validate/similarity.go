@@ -0,0 +1,268 @@
+package validate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"prompt-ci/internal/provider"
+	"prompt-ci/internal/suite"
+)
+
+// similarityNGrams is the largest word n-gram used by lexical similarity
+const similarityNGrams = 2
+
+const (
+	// embeddingProvider serves embeddings (OPENAI_API_KEY and OPENAI_BASE_URL)
+	embeddingProvider = "openai"
+	// embeddingTimeout bounds each call to the embeddings endpoint
+	embeddingTimeout = 30 * time.Second
+)
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// EmbeddingOptions configures how semantic_similarity reaches embeddings
+type EmbeddingOptions struct {
+	// CacheDir stores embeddings keyed by content hash ("" disables the cache)
+	CacheDir string
+	// Replay answers only from the embedding cache and never calls the endpoint
+	Replay bool
+}
+
+var (
+	embeddingMu      sync.Mutex
+	embeddingOptions EmbeddingOptions
+)
+
+// SetEmbeddingOptions replaces the embedding settings for the following runs
+func SetEmbeddingOptions(o EmbeddingOptions) {
+	embeddingMu.Lock()
+	defer embeddingMu.Unlock()
+	embeddingOptions = o
+}
+
+func init() {
+	Register("semantic_similarity", similarityValidator{})
+}
+
+type similarityValidator struct{}
+
+func (similarityValidator) Check(a suite.Assertion) error {
+	expected, err := expectString(a.Expected)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(expected) == "" {
+		return fmt.Errorf("expected must not be empty")
+	}
+	if a.Threshold == nil {
+		return fmt.Errorf("threshold is required for semantic_similarity")
+	}
+	if *a.Threshold < 0 || *a.Threshold > 1 {
+		return fmt.Errorf("threshold must be between 0.0 and 1.0, got %g", *a.Threshold)
+	}
+	return nil
+}
+
+func (similarityValidator) Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict {
+	expected, err := expectString(a.Expected)
+	if err != nil {
+		return verdict(false, err.Error())
+	}
+	threshold := 0.0
+	if a.Threshold != nil {
+		threshold = *a.Threshold
+	}
+
+	method := "lexical"
+	var score float64
+	var cost int
+	if a.EmbeddingModel != "" {
+		method = "embeddings " + a.EmbeddingModel
+		score, cost, err = embeddingSimilarity(ctx, a.EmbeddingModel, expected, content)
+		if err != nil {
+			v := verdictError(err)
+			v.CostMillicents = cost
+			return v
+		}
+	} else {
+		score = LexicalSimilarity(expected, content)
+	}
+
+	v := verdict(score >= threshold, "")
+	v.CostMillicents = cost
+	if !v.Passed {
+		v.Message = fmt.Sprintf("similarity %.3f is below threshold %.3f (%s)", score, threshold, method)
+	}
+	return v
+}
+
+// LexicalSimilarity returns the TF-IDF cosine similarity of a and b over
+// lowercase word unigrams and bigrams, between 0.0 and 1.0. The two texts
+// are the whole corpus, so shared n-grams weigh less than distinctive ones.
+func LexicalSimilarity(a, b string) float64 {
+	tfA := termFrequencies(a)
+	tfB := termFrequencies(b)
+	if len(tfA) == 0 || len(tfB) == 0 {
+		return 0
+	}
+
+	// Smoothed inverse document frequency over the two documents
+	idf := func(term string) float64 {
+		df := 0
+		if tfA[term] > 0 {
+			df++
+		}
+		if tfB[term] > 0 {
+			df++
+		}
+		return math.Log(3.0/float64(1+df)) + 1
+	}
+
+	vecA := make(map[string]float64, len(tfA))
+	for term, n := range tfA {
+		vecA[term] = float64(n) * idf(term)
+	}
+	vecB := make(map[string]float64, len(tfB))
+	for term, n := range tfB {
+		vecB[term] = float64(n) * idf(term)
+	}
+
+	var dot, normA, normB float64
+	for term, w := range vecA {
+		dot += w * vecB[term]
+		normA += w * w
+	}
+	for _, w := range vecB {
+		normB += w * w
+	}
+	return clampUnit(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+// termFrequencies counts the word n-grams of text
+func termFrequencies(text string) map[string]int {
+	words := wordRegex.FindAllString(strings.ToLower(text), -1)
+	counts := make(map[string]int)
+	for n := 1; n <= similarityNGrams; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			counts[strings.Join(words[i:i+n], " ")]++
+		}
+	}
+	return counts
+}
+
+// embeddingSimilarity embeds both texts with an OpenAI-compatible endpoint
+// and returns their cosine similarity and the cost of the embeddings call
+func embeddingSimilarity(ctx context.Context, model, a, b string) (float64, int, error) {
+	vectors, cost, err := embed(ctx, model, []string{a, b})
+	if err != nil {
+		return 0, cost, err
+	}
+	if len(vectors[0]) != len(vectors[1]) {
+		return 0, cost, fmt.Errorf("embedding sizes differ (%d and %d)", len(vectors[0]), len(vectors[1]))
+	}
+
+	var dot, normA, normB float64
+	for i := range vectors[0] {
+		dot += vectors[0][i] * vectors[1][i]
+		normA += vectors[0][i] * vectors[0][i]
+		normB += vectors[1][i] * vectors[1][i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, cost, nil
+	}
+	return clampUnit(dot / (math.Sqrt(normA) * math.Sqrt(normB))), cost, nil
+}
+
+// embed returns the embeddings of texts, from the cache when possible, and
+// the cost of requesting the ones that were not cached
+func embed(ctx context.Context, model string, texts []string) ([][]float64, int, error) {
+	embeddingMu.Lock()
+	opts := embeddingOptions
+	embeddingMu.Unlock()
+
+	vectors := make([][]float64, len(texts))
+	var missing []int
+	for i, text := range texts {
+		if v, ok := readCachedEmbedding(opts.CacheDir, embeddingCacheKey(model, text)); ok {
+			vectors[i] = v
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return vectors, 0, nil
+	}
+	if opts.Replay {
+		return nil, 0, fmt.Errorf("Replay mode: no cached embedding from %s:%s", embeddingProvider, model)
+	}
+
+	client, err := provider.NewOpenAI(provider.Config{Model: model})
+	if err != nil {
+		return nil, 0, err
+	}
+	inputs := make([]string, len(missing))
+	for j, i := range missing {
+		inputs[j] = texts[i]
+	}
+	ctx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+	defer cancel()
+	fetched, cost, err := client.Embed(ctx, model, inputs)
+	if err != nil {
+		return nil, cost, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	for j, i := range missing {
+		vectors[i] = fetched[j]
+		if err := writeCachedEmbedding(opts.CacheDir, embeddingCacheKey(model, texts[i]), fetched[j]); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write embedding cache entry: %v\n", err)
+		}
+	}
+	return vectors, cost, nil
+}
+
+// embeddingCacheKey computes SHA256(model 0x00 text), so a text such as an
+// expected answer is embedded once per model
+func embeddingCacheKey(model, text string) string {
+	h := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(h[:])
+}
+
+func readCachedEmbedding(dir, key string) ([]float64, bool) {
+	if dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(cacheEntryPath(dir, key))
+	if err != nil {
+		return nil, false
+	}
+	var v []float64
+	if err := json.Unmarshal(data, &v); err != nil || len(v) == 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+func writeCachedEmbedding(dir, key string, v []float64) error {
+	if dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return provider.WriteFileAtomic(cacheEntryPath(dir, key), data)
+}
+
+// clampUnit limits a cosine similarity to [0, 1] so opposed embeddings and
+// rounding error cannot produce scores outside the threshold range
+func clampUnit(x float64) float64 {
+	return math.Max(0, math.Min(1, x))
+}
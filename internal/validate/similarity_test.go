@@ -0,0 +1,47 @@
+package validate
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"prompt-ci/internal/suite"
+)
+
+func TestLexicalSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{name: "identical", a: "The build failed on main", b: "The build failed on main", min: 1, max: 1},
+		{name: "case and punctuation", a: "The build failed.", b: "the BUILD failed", min: 1, max: 1},
+		{name: "disjoint", a: "apples and pears", b: "quantum flux capacitor", min: 0, max: 0},
+		{name: "empty", a: "", b: "some text", min: 0, max: 0},
+		{name: "both empty", a: "", b: "", min: 0, max: 0},
+		{name: "only punctuation", a: "!!!", b: "!!!", min: 0, max: 0},
+		{name: "overlap", a: "the build failed on main", b: "the build passed on main", min: 0.2, max: 0.8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LexicalSimilarity(tt.a, tt.b)
+			if got < tt.min-1e-9 || got > tt.max+1e-9 {
+				t.Errorf("LexicalSimilarity(%q, %q) = %g, want between %g and %g", tt.a, tt.b, got, tt.min, tt.max)
+			}
+			if back := LexicalSimilarity(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("not symmetric: %g and %g", got, back)
+			}
+		})
+	}
+}
+
+func TestSemanticSimilarityLexical(t *testing.T) {
+	threshold := 0.9
+	a := suite.Assertion{Type: "semantic_similarity", Expected: "the build failed", Threshold: &threshold}
+	if v := ValidateAssertion(context.Background(), "The build failed.", a, &suite.Suite{}); !v.Passed || v.Err != nil {
+		t.Errorf("identical text: %+v, want a pass", v)
+	}
+	if v := ValidateAssertion(context.Background(), "all tests passed", a, &suite.Suite{}); v.Passed || v.Message == "" {
+		t.Errorf("different text: %+v, want a failure with a message", v)
+	}
+}
@@ -1,31 +1,176 @@
 package validate
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"prompt-ci/internal/provider"
 	"prompt-ci/internal/suite"
 )
 
 // Validator is the interface for all validators
 type Validator interface {
-	Validate(content string, expected interface{}) (bool, string)
+	// Check reports a problem with the assertion's expected value when the
+	// suite is loaded, before any case runs
+	Check(a suite.Assertion) error
+	// Validate checks one response against the assertion. Validators that
+	// call out (the judge, embeddings) stop when ctx is cancelled.
+	Validate(ctx context.Context, content string, a suite.Assertion, s *suite.Suite) Verdict
+}
+
+// Verdict is the outcome of checking one response against an assertion
+type Verdict struct {
+	Passed bool
+	// Message explains why the assertion failed
+	Message string
+	// Err is set when the assertion could not be evaluated (e.g. the judge
+	// was unreachable); such a verdict is neither a pass nor a failure
+	Err error
+	// Rationale is the judge's explanation, for llm_judge assertions
+	Rationale string
+	// Score is the partial credit of a group (nil means all or nothing)
+	Score *float64
+	// Branches are the results of the assertions inside a group
+	Branches []suite.AssertionResult
+	// CostMillicents is what the calls made to evaluate the assertion cost
+	// (the judge, embeddings), including those of nested assertions
+	CostMillicents int
+}
+
+// Result records the verdict for assertion a as an AssertionResult
+func (v Verdict) Result(a suite.Assertion) suite.AssertionResult {
+	r := suite.AssertionResult{
+		Type:      a.Type,
+		Weight:    a.EffectiveWeight(),
+		Passed:    v.Passed,
+		Rationale: v.Rationale,
+		Score:     v.Score,
+		Branches:  v.Branches,
+	}
+	if v.Err != nil {
+		r.Passed = false
+		r.Error = true
+		r.Message = v.Err.Error()
+	} else if !v.Passed {
+		r.Message = v.Message
+	}
+	return r
+}
+
+// verdict converts the (passed, message) pair returned by the Validate*
+// functions into a Verdict
+func verdict(passed bool, message string) Verdict {
+	return Verdict{Passed: passed, Message: message}
+}
+
+// verdictError returns the verdict for an assertion that could not be evaluated
+func verdictError(err error) Verdict {
+	return Verdict{Err: err}
+}
+
+// registry maps assertion types to their validators
+var registry = make(map[string]Validator)
+
+// Register adds a validator for an assertion type and makes the type valid
+// in suites. It panics if the type is already registered.
+func Register(assertionType string, v Validator) {
+	if _, exists := registry[assertionType]; exists {
+		panic(fmt.Sprintf("validate: assertion type '%s' registered twice", assertionType))
+	}
+	registry[assertionType] = v
+	suite.RegisterAssertionType(assertionType, v.Check)
+}
+
+// Lookup returns the validator registered for an assertion type
+func Lookup(assertionType string) (Validator, bool) {
+	v, ok := registry[assertionType]
+	return v, ok
+}
+
+// Types returns the registered assertion types in sorted order
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
 }
 
 // ValidateAssertion validates a single assertion against content
-func ValidateAssertion(content string, assertion suite.Assertion, s *suite.Suite) (bool, string) {
-	switch assertion.Type {
-	case "contains":
-		return ValidateContains(content, assertion.Expected)
-	case "regex":
-		return ValidateRegex(content, assertion.Expected)
-	case "json_schema":
-		return ValidateJSONSchema(content, assertion.Expected)
-	case "exact_match":
-		return ValidateExactMatch(content, assertion.Expected)
-	default:
-		return false, "unknown assertion type: " + assertion.Type
+func ValidateAssertion(ctx context.Context, content string, assertion suite.Assertion, s *suite.Suite) Verdict {
+	v, ok := Lookup(assertion.Type)
+	if !ok {
+		return verdictError(fmt.Errorf("unknown assertion type: %s (valid types: %s)", assertion.Type, strings.Join(Types(), ", ")))
+	}
+	return v.Validate(ctx, content, assertion, s)
+}
+
+// promptKey is the context key for the prompt behind the validated response
+type promptKey struct{}
+
+// WithPrompt returns a copy of ctx carrying the prompt (or rendered
+// conversation) that produced the response, for validators such as
+// llm_judge that grade the response in context
+func WithPrompt(ctx context.Context, prompt string) context.Context {
+	return context.WithValue(ctx, promptKey{}, prompt)
+}
+
+// promptFrom returns the prompt set by WithPrompt, or ""
+func promptFrom(ctx context.Context) string {
+	prompt, _ := ctx.Value(promptKey{}).(string)
+	return prompt
+}
+
+// CheckCredentials returns an error if an assertion in cases grades with a
+// provider whose credentials are missing, so a run fails before any case
+// instead of with an ERROR per assertion
+func CheckCredentials(cases []suite.Case) error {
+	for _, c := range cases {
+		for _, a := range c.AllAssertions() {
+			if err := checkAssertionCredentials(a); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
+
+func checkAssertionCredentials(a suite.Assertion) error {
+	if a.Type == "llm_judge" {
+		name := a.JudgeProvider
+		if name == "" {
+			name = DefaultJudgeProvider
+		}
+		if err := provider.CheckCredentials(name); err != nil {
+			return fmt.Errorf("llm_judge: %v", err)
+		}
+	}
+	if a.Type == "semantic_similarity" && a.EmbeddingModel != "" {
+		if err := provider.CheckCredentials(embeddingProvider); err != nil {
+			return fmt.Errorf("semantic_similarity: %v", err)
+		}
+	}
+	for _, nested := range a.Nested() {
+		if err := checkAssertionCredentials(nested); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ValidateGrounding validates grounding requirements for a response
 func ValidateGrounding(content string, s *suite.Suite) (bool, []string) {
 	return ValidateGroundingCitations(content, s)
 }
+
+// expectString returns expected as a string, or an error naming its type
+func expectString(expected interface{}) (string, error) {
+	str, ok := expected.(string)
+	if !ok {
+		return "", fmt.Errorf("expected must be a string, got %T", expected)
+	}
+	return str, nil
+}